@@ -15,6 +15,10 @@ func main() {
 	lgr.Infof("A little copying is better than a little dependency.")
 	lgr.Errorf("Errors are values. Documentation is for %s.", "users")
 	lgr.Debugf("Make the zero (%d) value useful.", 0)
+	lgr.Warnf("Don't panic.")
 
 	lgr.Infof("Hello, %d %v", 2025, time.Now())
+
+	shelfLgr := lgr.With(pocketlog.Field{Key: "shelf", Value: "sci-fi"})
+	shelfLgr.Infof("Clear is better than clever.")
 }
@@ -0,0 +1,160 @@
+package pocketlog
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy tells an AsyncWriter what to do with a write
+// when its queue is full.
+type OverflowPolicy byte
+
+const (
+	// OverflowBlock makes the write wait until there is room in the queue.
+	// No entry is lost, but a slow output slows the caller down.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop discards the write, which returns ErrQueueFull.
+	// The caller never waits, but entries can be lost.
+	OverflowDrop
+)
+
+var (
+	// ErrQueueFull is returned by an AsyncWriter dropping a write.
+	ErrQueueFull = errors.New("pocketlog: async queue is full")
+	// ErrClosed is returned when writing to a closed AsyncWriter.
+	ErrClosed = errors.New("pocketlog: writer is closed")
+)
+
+// AsyncWriter is an io.Writer that hands writes over to a background goroutine,
+// so that callers don't wait for a slow output. Writes are queued in order,
+// in a queue of bounded size, and what happens when it is full depends on
+// the OverflowPolicy.
+//
+// Flush waits for the queued writes to reach the output. Close must be called
+// to release the background goroutine once the writer is no longer used.
+type AsyncWriter struct {
+	output io.Writer
+	policy OverflowPolicy
+	queue  chan asyncItem
+	done   chan struct{}
+
+	// mu prevents writes from racing with Close.
+	mu     sync.RWMutex
+	closed bool
+
+	dropped atomic.Uint64
+
+	// err is the first error returned by the output since the last flush.
+	// It is only accessed by the background goroutine, and by Close once it is done.
+	err error
+}
+
+// asyncItem is either a line to write, or a flush request to acknowledge.
+type asyncItem struct {
+	line    []byte
+	flushed chan error
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to output,
+// queuing at most size writes. A size below 1 queues a single write.
+func NewAsyncWriter(output io.Writer, size int, policy OverflowPolicy) *AsyncWriter {
+	w := &AsyncWriter{
+		output: output,
+		policy: policy,
+		queue:  make(chan asyncItem, max(size, 1)),
+		done:   make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Write queues a copy of p to be written to the output.
+// The error of the output is reported by the next call to Flush.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	item := asyncItem{line: append([]byte(nil), p...)}
+
+	if w.policy == OverflowDrop {
+		select {
+		case w.queue <- item:
+		default:
+			w.dropped.Add(1)
+			return 0, ErrQueueFull
+		}
+		return len(p), nil
+	}
+
+	w.queue <- item
+	return len(p), nil
+}
+
+// Flush waits until every write queued before the call has reached the output.
+// It returns the first error the output returned since the previous flush.
+func (w *AsyncWriter) Flush() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	flushed := make(chan error)
+	w.queue <- asyncItem{flushed: flushed}
+	return <-flushed
+}
+
+// Close writes the queued entries to the output, then stops the background goroutine.
+// The output itself is not closed. It returns the first error the output returned
+// since the last flush.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+
+	return w.takeErr()
+}
+
+// Dropped returns the number of writes discarded because the queue was full.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// run writes the queued lines to the output, until the queue is closed.
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+
+	for item := range w.queue {
+		if item.flushed != nil {
+			item.flushed <- w.takeErr()
+			continue
+		}
+
+		if _, err := w.output.Write(item.line); err != nil && w.err == nil {
+			w.err = err
+		}
+	}
+}
+
+// takeErr returns the recorded error, and forgets it.
+func (w *AsyncWriter) takeErr() error {
+	err := w.err
+	w.err = nil
+	return err
+}
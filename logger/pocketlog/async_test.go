@@ -0,0 +1,197 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"fmt"
+	"learn-go-pockets/logger/pocketlog"
+	"sync"
+	"testing"
+)
+
+func TestAsyncWriter_KeepsOrder(t *testing.T) {
+	tw := &testWriter{}
+	aw := pocketlog.NewAsyncWriter(tw, 4, pocketlog.OverflowBlock)
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(aw), pocketlog.WithClock(fixedClock))
+	for i := range 50 {
+		testedLogger.Infof("entry %d", i)
+	}
+
+	if err := aw.Flush(); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+
+	lines := splitLines(tw.contents)
+	if len(lines) != 50 {
+		t.Fatalf("expected 50 log lines, got %d", len(lines))
+	}
+
+	for i, line := range lines {
+		expected := fmt.Sprintf(`{"time":"%s","level":"info","message":"entry %d"}`, fixedTimeText, i)
+		if line != expected {
+			t.Errorf("expected %s, got %s", expected, line)
+		}
+	}
+
+	if err := aw.Close(); err != nil {
+		t.Errorf("unexpected close error: %v", err)
+	}
+}
+
+func TestAsyncWriter_Drop(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{}), started: make(chan struct{}, 1)}
+	aw := pocketlog.NewAsyncWriter(bw, 2, pocketlog.OverflowDrop)
+
+	// the first write is picked up by the background goroutine, and blocks it
+	if _, err := aw.Write([]byte("1\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-bw.started
+
+	// the next two fill the queue
+	for _, line := range []string{"2\n", "3\n"} {
+		if _, err := aw.Write([]byte(line)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	n, err := aw.Write([]byte("4\n"))
+	if !errors.Is(err, pocketlog.ErrQueueFull) || n != 0 {
+		t.Errorf("expected 0, ErrQueueFull, got %d, %v", n, err)
+	}
+
+	if aw.Dropped() != 1 {
+		t.Errorf("expected 1 dropped write, got %d", aw.Dropped())
+	}
+
+	close(bw.release)
+	if err := aw.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	if bw.contents != "1\n2\n3\n" {
+		t.Errorf("expected the first 3 writes, got %q", bw.contents)
+	}
+}
+
+func TestAsyncWriter_MinimumSize(t *testing.T) {
+	for _, size := range []int{-1, 0} {
+		tw := &testWriter{}
+		aw := pocketlog.NewAsyncWriter(tw, size, pocketlog.OverflowDrop)
+
+		if _, err := aw.Write([]byte("1\n")); err != nil {
+			t.Errorf("expected a write to be queued with size %d, got %v", size, err)
+		}
+
+		if err := aw.Close(); err != nil {
+			t.Fatalf("unexpected close error: %v", err)
+		}
+
+		if tw.contents != "1\n" {
+			t.Errorf("expected the write with size %d, got %q", size, tw.contents)
+		}
+	}
+}
+
+func TestAsyncWriter_Close(t *testing.T) {
+	tw := &testWriter{}
+	aw := pocketlog.NewAsyncWriter(tw, 16, pocketlog.OverflowBlock)
+
+	_, _ = aw.Write([]byte("before close\n"))
+
+	if err := aw.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	if tw.contents != "before close\n" {
+		t.Errorf("expected queued writes to be written on close, got %q", tw.contents)
+	}
+
+	if _, err := aw.Write([]byte("after close\n")); !errors.Is(err, pocketlog.ErrClosed) {
+		t.Errorf("expected ErrClosed on write, got %v", err)
+	}
+
+	if err := aw.Flush(); !errors.Is(err, pocketlog.ErrClosed) {
+		t.Errorf("expected ErrClosed on flush, got %v", err)
+	}
+
+	if err := aw.Close(); !errors.Is(err, pocketlog.ErrClosed) {
+		t.Errorf("expected ErrClosed on second close, got %v", err)
+	}
+}
+
+func TestAsyncWriter_FlushReportsOutputErrors(t *testing.T) {
+	errDiskFull := errors.New("disk full")
+	aw := pocketlog.NewAsyncWriter(failingWriter{err: errDiskFull}, 4, pocketlog.OverflowBlock)
+	defer aw.Close()
+
+	_, _ = aw.Write([]byte("lost\n"))
+
+	if err := aw.Flush(); !errors.Is(err, errDiskFull) {
+		t.Errorf("expected %v, got %v", errDiskFull, err)
+	}
+
+	if err := aw.Flush(); err != nil {
+		t.Errorf("expected the error to be reported once, got %v", err)
+	}
+}
+
+func TestAsyncWriter_Concurrent(t *testing.T) {
+	tw := &testWriter{}
+	aw := pocketlog.NewAsyncWriter(tw, 8, pocketlog.OverflowBlock)
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(aw))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				testedLogger.Infof("entry %d", i)
+				if i%10 == 0 {
+					_ = aw.Flush()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := aw.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	if lines := splitLines(tw.contents); len(lines) != 400 {
+		t.Errorf("expected 400 log lines, got %d", len(lines))
+	}
+}
+
+// blockingWriter is an io.Writer that blocks until release is closed.
+// It signals on started when its first write begins.
+type blockingWriter struct {
+	release  chan struct{}
+	started  chan struct{}
+	contents string
+}
+
+// Write implements the io.Writer interface.
+func (bw *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case bw.started <- struct{}{}:
+	default:
+	}
+
+	<-bw.release
+	bw.contents += string(p)
+	return len(p), nil
+}
+
+// failingWriter is an io.Writer that always fails with err.
+type failingWriter struct {
+	err error
+}
+
+// Write implements the io.Writer interface.
+func (fw failingWriter) Write([]byte) (int, error) {
+	return 0, fw.err
+}
@@ -0,0 +1,56 @@
+package pocketlog
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// callerDepth is the number of frames between runtime.Callers and the code
+// calling a logging method: runtime.Callers itself, callerPC, logf
+// and the logging method.
+const callerDepth = 4
+
+// callerPC returns the program counter of the code calling a logging method,
+// skipping skip additional frames.
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(callerDepth+skip, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// setCaller fills the caller and function of the entry from a program counter.
+func (e *Entry) setCaller(pc uintptr) {
+	if pc == 0 {
+		return
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return
+	}
+
+	e.pc = pc
+	e.Caller = shortPath(frame.File) + ":" + strconv.Itoa(frame.Line)
+	e.Func = frame.Function
+}
+
+// shortPath returns the last directory and the file name of a path,
+// which is usually enough to find a file in a project.
+func shortPath(path string) string {
+	dir, file := filepath.Split(path)
+	return filepath.Join(filepath.Base(dir), file)
+}
+
+// splitCaller returns the file and the line of the caller of an entry,
+// as written in its Caller.
+func splitCaller(caller string) (file, line string) {
+	i := strings.LastIndexByte(caller, ':')
+	if i < 0 {
+		return caller, ""
+	}
+	return caller[:i], caller[i+1:]
+}
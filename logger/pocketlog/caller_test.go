@@ -0,0 +1,105 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"learn-go-pockets/logger/pocketlog"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+func TestLogger_WithCaller(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithCaller(0))
+
+	testedLogger.Infof(infoMessage)
+	expectedCaller := callerLine(-1)
+
+	assertCaller(t, tw.contents, expectedCaller, "learn-go-pockets/logger/pocketlog_test.TestLogger_WithCaller")
+}
+
+func TestLogger_WithCallerSkip(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithCaller(1))
+
+	logInfo(testedLogger, infoMessage)
+	expectedCaller := callerLine(-1)
+
+	assertCaller(t, tw.contents, expectedCaller, "learn-go-pockets/logger/pocketlog_test.TestLogger_WithCallerSkip")
+}
+
+func TestSlogHandler_WithCaller(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithCaller(0))
+
+	slog.New(pocketlog.NewSlogHandler(testedLogger)).Info(infoMessage)
+	expectedCaller := callerLine(-1)
+
+	assertCaller(t, tw.contents, expectedCaller, "learn-go-pockets/logger/pocketlog_test.TestSlogHandler_WithCaller")
+}
+
+func TestLogger_WithoutCaller(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+	testedLogger.Infof(infoMessage)
+
+	assertCaller(t, tw.contents, "", "")
+}
+
+func BenchmarkLogger_Caller(b *testing.B) {
+	for name, opts := range map[string][]pocketlog.Option{
+		"disabled": {pocketlog.WithOutput(io.Discard)},
+		"enabled":  {pocketlog.WithOutput(io.Discard), pocketlog.WithCaller(0)},
+	} {
+		b.Run(name, func(b *testing.B) {
+			lgr := pocketlog.New(pocketlog.LevelInfo, opts...)
+			b.ReportAllocs()
+			for b.Loop() {
+				lgr.Infof(infoMessage)
+			}
+		})
+	}
+}
+
+// logInfo is a wrapper around a logger, adding a frame to the stack.
+func logInfo(lgr *pocketlog.Logger, msg string) {
+	lgr.Infof("%s", msg)
+}
+
+// callerLine returns the caller of the test function as written in entries,
+// shifted by offset lines.
+func callerLine(offset int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("pocketlog/%s:%d", fileName(file), line+offset)
+}
+
+// fileName returns the last element of a slash-separated path.
+func fileName(path string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '/' {
+			return path[i+1:]
+		}
+	}
+	return path
+}
+
+// assertCaller checks the caller and function of a single JSON entry.
+func assertCaller(t *testing.T, line, expectedCaller, expectedFunc string) {
+	t.Helper()
+
+	var got pocketlog.LogEntry
+	if err := json.Unmarshal([]byte(line), &got); err != nil {
+		t.Fatalf("invalid JSON log: %v", err)
+	}
+
+	if got.Caller != expectedCaller {
+		t.Errorf("expected caller %q, got %q", expectedCaller, got.Caller)
+	}
+
+	if got.Func != expectedFunc {
+		t.Errorf("expected func %q, got %q", expectedFunc, got.Func)
+	}
+}
@@ -0,0 +1,56 @@
+package pocketlog
+
+// GCPEncoder returns a JSONEncoder following the structured logging schema of
+// Google Cloud Logging: the level is written as the severity, the caller as
+// the source location, and the fields as the JSON payload.
+func GCPEncoder() JSONEncoder {
+	return JSONEncoder{
+		Keys:           JSONKeys{Level: "severity"},
+		LevelText:      gcpSeverity,
+		SourceLocation: "logging.googleapis.com/sourceLocation",
+	}
+}
+
+// gcpSeverity returns the LogSeverity of Cloud Logging matching lvl.
+func gcpSeverity(lvl Level) string {
+	switch lvl {
+	case LevelTrace, LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARNING"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "CRITICAL"
+	}
+}
+
+// ECSEncoder returns a JSONEncoder following the Elastic Common Schema,
+// with dotted keys such as log.level, which Elasticsearch reads as nested objects.
+func ECSEncoder() JSONEncoder {
+	return JSONEncoder{
+		Keys: JSONKeys{
+			Time:   "@timestamp",
+			Level:  "log.level",
+			Logger: "log.logger",
+			Caller: "log.origin.file.name",
+			Line:   "log.origin.file.line",
+			Func:   "log.origin.function",
+		},
+	}
+}
+
+// DatadogEncoder returns a JSONEncoder following the reserved attributes of Datadog:
+// the level is written as the status, and the logger as logger.name.
+func DatadogEncoder() JSONEncoder {
+	return JSONEncoder{
+		Keys: JSONKeys{
+			Time:   "timestamp",
+			Level:  "status",
+			Logger: "logger.name",
+			Func:   "logger.method_name",
+		},
+	}
+}
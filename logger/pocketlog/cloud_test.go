@@ -0,0 +1,105 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"learn-go-pockets/logger/pocketlog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestJSONEncoder_Presets(t *testing.T) {
+	entries := []pocketlog.Entry{
+		{
+			Time:    fixedTime,
+			Level:   pocketlog.LevelDebug,
+			Message: "Loading shelves",
+		},
+		{
+			Time:    fixedTime,
+			Level:   pocketlog.LevelWarn,
+			Message: "Shelf is empty",
+			Logger:  "bookworms.loader",
+			Caller:  "bookworms/loader.go:42",
+			Func:    "bookworms.loadShelf",
+			Fields: []pocketlog.Field{
+				{Key: "shelf", Value: "sci-fi"},
+				{Key: "count", Value: 0},
+			},
+		},
+		{
+			Time:    fixedTime,
+			Level:   pocketlog.LevelFatal,
+			Message: "Can't open library",
+			Fields: []pocketlog.Field{
+				// these collide with keys of some of the presets
+				{Key: "severity", Value: "high"},
+				{Key: "status", Value: "closed"},
+				{Key: "@timestamp", Value: "yesterday"},
+				{Key: "message", Value: "duplicate"},
+			},
+		},
+	}
+
+	tests := map[string]pocketlog.JSONEncoder{
+		"default": {},
+		"gcp":     pocketlog.GCPEncoder(),
+		"ecs":     pocketlog.ECSEncoder(),
+		"datadog": pocketlog.DatadogEncoder(),
+	}
+
+	for name, enc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			for _, e := range entries {
+				if err := enc.Encode(&buf, e); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			for _, line := range splitLines(buf.String()) {
+				if !json.Valid([]byte(line)) {
+					t.Errorf("invalid JSON: %s", line)
+				}
+			}
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatalf("can't update golden file: %v", err)
+				}
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("can't read golden file: %v", err)
+			}
+
+			if buf.String() != string(expected) {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestJSONEncoder_Keys(t *testing.T) {
+	tw := &testWriter{}
+	enc := pocketlog.JSONEncoder{Keys: pocketlog.JSONKeys{Time: "ts", Message: "msg"}}
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithTimeFormat(pocketlog.TimeFormatUnixMilli),
+		pocketlog.WithEncoder(enc),
+	)
+
+	lgr.With(pocketlog.Field{Key: "time", Value: "now"}).Infof(infoMessage)
+
+	expected := `{"ts":1741964966535,"level":"info","msg":"` + infoMessage + `","time":"now"}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %s, got %s", expected, tw.contents)
+	}
+}
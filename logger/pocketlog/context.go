@@ -0,0 +1,117 @@
+package pocketlog
+
+import "context"
+
+// contextKey is the type of the keys of the values pocketlog stores in a context.
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+	traceIDKey
+	spanIDKey
+)
+
+// contextField is a context value to be added to entries as a field.
+type contextField struct {
+	field string
+	key   any
+}
+
+// NewContext returns a copy of ctx that carries the logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in ctx by NewContext, if any.
+func FromContext(ctx context.Context) (*Logger, bool) {
+	l, ok := ctx.Value(loggerKey).(*Logger)
+	return l, ok
+}
+
+// ContextWithRequestID returns a copy of ctx that carries a request ID.
+// Entries logged with this context hold it in the request_id field.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// ContextWithTraceID returns a copy of ctx that carries a trace ID.
+// Entries logged with this context hold it in the trace_id field.
+func ContextWithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey, id)
+}
+
+// ContextWithSpanID returns a copy of ctx that carries a span ID.
+// Entries logged with this context hold it in the span_id field.
+func ContextWithSpanID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, spanIDKey, id)
+}
+
+// TracefCtx is Tracef, with the fields registered in the context.
+func (l *Logger) TracefCtx(ctx context.Context, format string, args ...any) {
+	if l.discards(LevelTrace) {
+		return
+	}
+
+	l.logf(ctx, LevelTrace, format, args...)
+}
+
+// DebugfCtx is Debugf, with the fields registered in the context.
+func (l *Logger) DebugfCtx(ctx context.Context, format string, args ...any) {
+	if l.discards(LevelDebug) {
+		return
+	}
+
+	l.logf(ctx, LevelDebug, format, args...)
+}
+
+// InfofCtx is Infof, with the fields registered in the context.
+func (l *Logger) InfofCtx(ctx context.Context, format string, args ...any) {
+	if l.discards(LevelInfo) {
+		return
+	}
+
+	l.logf(ctx, LevelInfo, format, args...)
+}
+
+// WarnfCtx is Warnf, with the fields registered in the context.
+func (l *Logger) WarnfCtx(ctx context.Context, format string, args ...any) {
+	if l.discards(LevelWarn) {
+		return
+	}
+
+	l.logf(ctx, LevelWarn, format, args...)
+}
+
+// ErrorfCtx is Errorf, with the fields registered in the context.
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, args ...any) {
+	if l.discards(LevelError) {
+		return
+	}
+
+	l.logf(ctx, LevelError, format, args...)
+}
+
+// FatalfCtx is Fatalf, with the fields registered in the context.
+func (l *Logger) FatalfCtx(ctx context.Context, format string, args ...any) {
+	l.logf(ctx, LevelFatal, format, args...)
+	_ = l.Flush()
+	l.exit(1)
+}
+
+// withContextFields returns the fields of the logger,
+// followed by the registered values found in ctx.
+func (l *Logger) withContextFields(ctx context.Context) []Field {
+	var fields []Field
+	for _, cf := range l.contextKeys {
+		if v := ctx.Value(cf.key); v != nil {
+			fields = append(fields, Field{Key: cf.field, Value: v})
+		}
+	}
+
+	if len(fields) == 0 {
+		return l.fields
+	}
+
+	return mergeFields(l.fields, fields)
+}
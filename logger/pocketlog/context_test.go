@@ -0,0 +1,109 @@
+package pocketlog_test
+
+import (
+	"context"
+	"learn-go-pockets/logger/pocketlog"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// tenantKey is the type of a context key defined by the application.
+type tenantKey struct{}
+
+func ExampleLogger_InfofCtx() {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithClock(fixedClock))
+
+	ctx := pocketlog.ContextWithRequestID(context.Background(), "f3a9")
+	lgr.InfofCtx(ctx, "Shelf %s loaded", "sci-fi")
+	// Output:
+	// {"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"Shelf sci-fi loaded","request_id":"f3a9"}
+}
+
+func TestFromContext(t *testing.T) {
+	if _, ok := pocketlog.FromContext(context.Background()); ok {
+		t.Errorf("expected no logger in an empty context")
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelInfo)
+	ctx := pocketlog.NewContext(context.Background(), lgr)
+
+	got, ok := pocketlog.FromContext(ctx)
+	if !ok || got != lgr {
+		t.Errorf("expected the stored logger, got %v, %t", got, ok)
+	}
+}
+
+func TestLogger_CtxVariants(t *testing.T) {
+	ctx := context.Background()
+	ctx = pocketlog.ContextWithRequestID(ctx, "req-1")
+	ctx = pocketlog.ContextWithTraceID(ctx, "4bf92f3577b34da6a3ce929d0e0e4736")
+	ctx = pocketlog.ContextWithSpanID(ctx, "00f067aa0ba902b7")
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelTrace,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithContextKey("tenant", tenantKey{}),
+	).With(pocketlog.Field{Key: "service", Value: "bookworms"})
+
+	testedLogger.TracefCtx(ctx, "trace")
+	testedLogger.DebugfCtx(ctx, "debug")
+	testedLogger.InfofCtx(ctx, "info")
+	testedLogger.WarnfCtx(ctx, "warn")
+	testedLogger.ErrorfCtx(ctx, "error")
+
+	lines := splitLines(tw.contents)
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 log lines, got %d", len(lines))
+	}
+
+	const expectedFields = " service=bookworms request_id=req-1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 tenant=acme"
+	for _, line := range lines {
+		if !strings.HasSuffix(line, expectedFields) {
+			t.Errorf("expected %s to end with the context fields", line)
+		}
+	}
+}
+
+func TestLogger_CtxVariantsThreshold(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelError, pocketlog.WithOutput(tw))
+
+	ctx := pocketlog.ContextWithRequestID(context.Background(), "req-1")
+	testedLogger.InfofCtx(ctx, "hidden")
+
+	if tw.contents != "" {
+		t.Errorf("expected no output, got %s", tw.contents)
+	}
+}
+
+func TestLogger_FatalfCtx(t *testing.T) {
+	tw := &testWriter{}
+
+	exitCode := -1
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithExitFunc(func(code int) { exitCode = code }),
+	)
+
+	ctx := pocketlog.ContextWithRequestID(context.Background(), "req-1")
+	testedLogger.FatalfCtx(ctx, "bye")
+
+	if exitCode != 1 || !strings.Contains(tw.contents, `"request_id":"req-1"`) {
+		t.Errorf("expected an exit and the request ID, got %d and %s", exitCode, tw.contents)
+	}
+}
+
+func TestSlogHandler_Context(t *testing.T) {
+	tw := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+	ctx := pocketlog.ContextWithTraceID(context.Background(), "4bf92f35")
+	slog.New(pocketlog.NewSlogHandler(testedLogger)).InfoContext(ctx, "hello", "user", "ada")
+
+	if !strings.HasSuffix(tw.contents, `"trace_id":"4bf92f35","user":"ada"}`+"\n") {
+		t.Errorf("expected the trace ID from the context, got %s", tw.contents)
+	}
+}
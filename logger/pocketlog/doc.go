@@ -5,11 +5,80 @@ First, instantiate a logger with pocketlog.New, and giving it a
 threshold level and an optional configuration option.
 Messages of lesser criticality won't be logged.
 
-Sharing the logger is the responsibility of the caller.
+A logger, and the loggers derived from it, can be shared between goroutines:
+each entry is written to the output as a whole. To keep slow outputs off
+hot paths, wrap them in an AsyncWriter. To keep log files from growing
+without bound, write to a RotatingFile.
 
-The logger can be called to log messages on three levels:
+The logger can be called to log messages on six levels:
+  - Trace: the finest details, such as the values going through a loop
   - Debug: mostly used to debug code, follow step-by-step processes
   - Info: valuable messages providing insights to the milestones of a process
+  - Warn: unexpected situations the process can recover from
   - Error: error messages to understand what went wrong
+  - Fatal: errors the process can't go on after, it exits once they are logged
+
+The threshold can be changed while the logger runs with Logger.SetLevel,
+or over HTTP by mounting Logger.LevelHandler on an admin endpoint.
+
+Levels can be read from text with ParseLevel, or through their
+encoding.TextUnmarshaler implementation, e.g. with flag.TextVar.
+
+Every entry is timestamped. Use WithClock to control the time source,
+and WithTimeFormat to pick between RFC 3339, Unix milliseconds,
+or a layout of your own.
+
+Entries are written as JSON by default.
+Use WithEncoder to switch to logfmt, to an aligned text format
+that is easier on the eyes during development, or to your own Encoder.
+WithSink adds more outputs, each with its own encoder and threshold,
+e.g. to send errors to Stderr as well. Without WithOutput or WithEncoder,
+the sinks replace Stdout.
+For syslog servers and journald, pair DialSyslog or DialJournal with
+a SyslogEncoder or a JournalEncoder. To export entries to an OpenTelemetry
+collector, pair NewOTLPWriter with an OTLPEncoder.
+
+The keys written by the JSONEncoder can be renamed, and GCPEncoder,
+ECSEncoder and DatadogEncoder follow the schemas of these cloud services.
+
+Errors of the outputs are reported to the function given to WithErrorHandler,
+and WithFallback writes the entries an output fails to write elsewhere,
+such as to Stderr. Logger.WriteStats counts written, failed and dropped entries.
+Logger.LevelStats counts the entries emitted and suppressed at every level.
+These counts can be published with expvar through Logger.Expvar, or served
+to Prometheus by Logger.MetricsHandler.
+
+Code using log/slog can write through a Logger with NewSlogHandler,
+and a Logger can forward its entries to any slog.Handler with WithSlogHandler.
+
+Logger.Named derives a logger whose dotted name, such as bookworms.loader,
+is written in its entries. A LevelSpec, such as "info,bookworms=debug",
+sets the threshold of named loggers and of the ones derived from them.
+
+Use Logger.With to derive a logger that adds key/value fields,
+such as a request ID, to every entry it writes. Fields are best built with
+the typed constructors, such as String, Int or Duration, and Group nests
+them, which the JSON encoder writes natively. Types implementing LogValuer
+choose how they are logged. Err records an error
+along with the tree of its causes.
+
+Code writing through the standard log package can be routed to a Logger
+with RedirectStdLog, or handed a log.Logger built by NewStdLogger.
+
+A logger can travel in a context.Context with NewContext and FromContext.
+The methods taking a context, such as InfofCtx, add the request, trace and
+span IDs it carries to the entry, as well as the keys registered with WithContextKey.
+
+Tests can capture and check the entries of a logger with the pocketlogtest package.
+
+Sensitive values are kept out of entries by implementing Redactor,
+or by configuring the logger with WithRedactedKeys and WithRedactedPatterns.
+
+WithFlightRecorder keeps the last entries below the threshold in memory,
+and writes them, marked as backfill, before the next error.
+
+Entries below the threshold cost next to nothing. Values that are expensive
+to compute can be wrapped in a Lazy function, only called when the entry is
+written, or guarded by Logger.Enabled.
 */
 package pocketlog
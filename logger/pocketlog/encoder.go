@@ -0,0 +1,139 @@
+package pocketlog
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Encoder turns entries into bytes ready to be written to the output.
+type Encoder interface {
+	// Encode writes the entry to buf as a single record, terminated
+	// by a newline unless the format leaves the framing to the transport.
+	Encode(buf *bytes.Buffer, e Entry) error
+}
+
+// Entry is a single log event, as handed over to an Encoder.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	// Logger is the name of the logger that wrote the entry, see Logger.Named.
+	Logger string
+	// Caller is the file and line of the code that logged the entry,
+	// and Func the name of its function. Both are only set with WithCaller.
+	Caller string
+	Func   string
+	Fields []Field
+
+	// pc is the program counter of the caller, if it was captured.
+	pc uintptr
+	// timeFormat is the time format configured on the logger.
+	timeFormat string
+}
+
+// appendTime appends the entry's time as text to dst, following the logger's time format.
+func (e Entry) appendTime(dst []byte) []byte {
+	switch e.timeFormat {
+	case "":
+		return e.Time.AppendFormat(dst, TimeFormatRFC3339Nano)
+	case TimeFormatUnixMilli:
+		return strconv.AppendInt(dst, e.Time.UnixMilli(), 10)
+	default:
+		return e.Time.AppendFormat(dst, e.timeFormat)
+	}
+}
+
+// plainTime tells whether the text of the entry's time holds nothing
+// to quote or escape, which is the case of the built-in time formats.
+func (e Entry) plainTime() bool {
+	switch e.timeFormat {
+	case "", TimeFormatRFC3339Nano, TimeFormatUnixMilli:
+		return true
+	default:
+		return false
+	}
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString writes s to buf as a quoted JSON string.
+// Quotes, backslashes and control characters are escaped,
+// and invalid UTF-8 is replaced by the Unicode replacement character.
+func appendJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+// appendEscaped writes s to buf without quoting it,
+// escaping control characters so that it stays on a single line.
+func appendEscaped(buf *bytes.Buffer, s string) {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c != 0x7f {
+			continue
+		}
+
+		buf.WriteString(s[start:i])
+		switch c {
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.WriteString(`\x`)
+			buf.WriteByte(hexDigits[c>>4])
+			buf.WriteByte(hexDigits[c&0xf])
+		}
+		start = i + 1
+	}
+
+	buf.WriteString(s[start:])
+}
@@ -0,0 +1,115 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"learn-go-pockets/logger/pocketlog"
+	"testing"
+)
+
+func ExampleLogfmtEncoder() {
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+	)
+	lgr.With(pocketlog.Field{Key: "shelf", Value: "sci-fi"}).Infof("%d books loaded", 3)
+	// Output:
+	// time=2025-03-14T15:09:26.535897932Z level=info message="3 books loaded" shelf=sci-fi
+}
+
+func ExampleTextEncoder() {
+	lgr := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithEncoder(pocketlog.TextEncoder{}),
+	)
+	lgr.Debugf("Loading shelves")
+	lgr.With(pocketlog.Field{Key: "shelf", Value: "sci-fi"}).Errorf("Shelf is empty")
+	// Output:
+	// 2025-03-14T15:09:26.535897932Z [debug] Loading shelves
+	// 2025-03-14T15:09:26.535897932Z [error] Shelf is empty  shelf=sci-fi
+}
+
+func TestEncoders(t *testing.T) {
+	entry := pocketlog.Entry{
+		Time:    fixedTime,
+		Level:   pocketlog.LevelInfo,
+		Message: "She said \"hi\"\nthen left",
+		Fields: []pocketlog.Field{
+			{Key: "path", Value: `C:\books`},
+			{Key: "count", Value: 2},
+			{Key: "tags", Value: []string{"a b", "c"}},
+			{Key: "empty", Value: ""},
+			{Key: "err", Value: errors.New("no such shelf")},
+			{Key: "my key", Value: "a=b"},
+		},
+	}
+
+	tests := map[string]struct {
+		encoder  pocketlog.Encoder
+		expected string
+	}{
+		"json": {
+			encoder: pocketlog.JSONEncoder{},
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"She said \"hi\"\nthen left",` +
+				`"path":"C:\\books","count":2,"tags":["a b","c"],"empty":"","err":"no such shelf","my key":"a=b"}` + "\n",
+		},
+		"logfmt": {
+			encoder: pocketlog.LogfmtEncoder{},
+			expected: `time=2025-03-14T15:09:26.535897932Z level=info message="She said \"hi\"\nthen left" ` +
+				`path="C:\\books" count=2 tags="[\"a b\",\"c\"]" empty="" err="no such shelf" my_key="a=b"` + "\n",
+		},
+		"text": {
+			encoder: pocketlog.TextEncoder{},
+			expected: `2025-03-14T15:09:26.535897932Z [info ] She said "hi"\nthen left  ` +
+				`path="C:\\books" count=2 tags="[\"a b\",\"c\"]" empty="" err="no such shelf" my_key="a=b"` + "\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.encoder.Encode(&buf, entry); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestJSONEncoder_Escaping(t *testing.T) {
+	messages := []string{
+		"plain",
+		"quotes \" and backslashes \\",
+		"new\nline, carriage\rreturn, tab\t",
+		"control \x00\x1f characters",
+		"html <b>&amp;</b>",
+		"unicode ☕ 🤭",
+		"invalid \xff utf-8",
+	}
+
+	for _, msg := range messages {
+		var buf bytes.Buffer
+		err := pocketlog.JSONEncoder{}.Encode(&buf, pocketlog.Entry{Time: fixedTime, Message: msg})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+			t.Errorf("expected a single line for %q, got %q", msg, buf.String())
+		}
+
+		var got pocketlog.LogEntry
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("invalid JSON log %q: %v", buf.String(), err)
+		}
+
+		expected := string([]rune(msg)) // invalid UTF-8 turns into U+FFFD
+		if got.Message != expected {
+			t.Errorf("expected %q, got %q", expected, got.Message)
+		}
+	}
+}
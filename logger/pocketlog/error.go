@@ -0,0 +1,130 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// errorKey is the key of the field created by Err.
+const errorKey = "error"
+
+// maxErrorDepth bounds how deep the causes of an error are followed.
+const maxErrorDepth = 32
+
+// Err returns a field recording err under the "error" key. In JSON, it is an
+// object holding the message and type of the error, and the tree of its causes,
+// as returned by Unwrap, including the errors joined with errors.Join.
+// With WithErrorStack, it also holds the stack of the code that logged it.
+func Err(err error) Field {
+	return Field{Key: errorKey, Value: errorValue{err: err}}
+}
+
+// errorValue is the value of the field created by Err.
+type errorValue struct {
+	err   error
+	stack []string
+	// redactor hides the sensitive parts of the messages, if set.
+	redactor *redactor
+}
+
+// errorJSON is the JSON structure of an error and its causes.
+type errorJSON struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Causes  []errorJSON `json:"causes,omitempty"`
+	Stack   []string    `json:"stack,omitempty"`
+}
+
+// String returns the message of the error, which is what text encoders write.
+func (v errorValue) String() string {
+	if v.err == nil {
+		return "<nil>"
+	}
+	return v.redactor.redactString(v.err.Error())
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v errorValue) MarshalJSON() ([]byte, error) {
+	if v.err == nil {
+		return []byte("null"), nil
+	}
+
+	tree := errorTree(v.err, v.redactor, 0)
+	tree.Stack = v.stack
+
+	return json.Marshal(tree)
+}
+
+// errorTree returns the JSON structure of err and its causes,
+// where the redactor hides the sensitive parts of the messages.
+func errorTree(err error, r *redactor, depth int) errorJSON {
+	tree := errorJSON{Message: r.redactString(err.Error()), Type: fmt.Sprintf("%T", err)}
+	if depth >= maxErrorDepth {
+		return tree
+	}
+
+	var causes []error
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			causes = []error{cause}
+		}
+	case interface{ Unwrap() []error }:
+		causes = u.Unwrap()
+	}
+
+	for _, cause := range causes {
+		if cause != nil {
+			tree.Causes = append(tree.Causes, errorTree(cause, r, depth+1))
+		}
+	}
+
+	return tree
+}
+
+// withErrorStacks returns the fields, where the values created by Err hold the stack
+// found skip frames above the caller. Fields are only copied if one was changed.
+func withErrorStacks(fields []Field, skip int) []Field {
+	var stack []string
+	copied := false
+	for i, f := range fields {
+		ev, ok := f.Value.(errorValue)
+		if !ok || ev.stack != nil {
+			continue
+		}
+
+		if stack == nil {
+			stack = captureStack(skip + 1)
+		}
+
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		ev.stack = stack
+		fields[i].Value = ev
+	}
+
+	return fields
+}
+
+// captureStack returns the frames of the stack above the code calling captureStack,
+// skipping skip additional frames, as "function file:line".
+func captureStack(skip int) []string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack []string
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame.Function+" "+shortPath(frame.File)+":"+strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+
+	return stack
+}
@@ -0,0 +1,151 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"learn-go-pockets/logger/pocketlog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func ExampleErr() {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithClock(fixedClock))
+
+	err := fmt.Errorf("loading shelf: %w", fs.ErrNotExist)
+	lgr.With(pocketlog.Err(err)).Errorf("Shelf unavailable")
+	// Output:
+	// {"time":"2025-03-14T15:09:26.535897932Z","level":"error","message":"Shelf unavailable","error":{"message":"loading shelf: file does not exist","type":"*fmt.wrapError","causes":[{"message":"file does not exist","type":"*errors.errorString"}]}}
+}
+
+// errorTree is the JSON structure of the field written by pocketlog.Err.
+type errorTree struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Causes  []errorTree `json:"causes"`
+	Stack   []string    `json:"stack"`
+}
+
+func TestErr(t *testing.T) {
+	_, pathErr := os.Open("/does/not/exist")
+	errTimeout := errors.New("timeout")
+
+	tests := map[string]struct {
+		err      error
+		expected errorTree
+	}{
+		"plain": {
+			err:      errTimeout,
+			expected: errorTree{Message: "timeout", Type: "*errors.errorString"},
+		},
+		"wrapped chain": {
+			err: fmt.Errorf("loading: %w", pathErr),
+			expected: errorTree{
+				Message: "loading: open /does/not/exist: no such file or directory",
+				Type:    "*fmt.wrapError",
+				Causes: []errorTree{{
+					Message: "open /does/not/exist: no such file or directory",
+					Type:    "*fs.PathError",
+					Causes:  []errorTree{{Message: "no such file or directory", Type: "syscall.Errno"}},
+				}},
+			},
+		},
+		"joined tree": {
+			err: errors.Join(errTimeout, fmt.Errorf("retry: %w", errTimeout)),
+			expected: errorTree{
+				Message: "timeout\nretry: timeout",
+				Type:    "*errors.joinError",
+				Causes: []errorTree{
+					{Message: "timeout", Type: "*errors.errorString"},
+					{
+						Message: "retry: timeout",
+						Type:    "*fmt.wrapError",
+						Causes:  []errorTree{{Message: "timeout", Type: "*errors.errorString"}},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+			lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+			lgr.With(pocketlog.Err(tc.err)).Errorf("failed")
+
+			got := decodeErrorField(t, tw.contents)
+			if fmt.Sprint(got) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestErr_Nil(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+	lgr.With(pocketlog.Err(nil)).Errorf("failed")
+
+	if !strings.HasSuffix(tw.contents, `"error":null}`+"\n") {
+		t.Errorf("expected a null error, got %s", tw.contents)
+	}
+}
+
+func TestErr_Text(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}))
+
+	lgr.With(pocketlog.Err(errors.New("disk full"))).Errorf("failed")
+
+	if !strings.HasSuffix(tw.contents, ` error="disk full"`+"\n") {
+		t.Errorf("expected the error message, got %s", tw.contents)
+	}
+}
+
+func TestLogger_WithErrorStack(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithErrorStack())
+
+	errLgr := lgr.With(pocketlog.Err(errors.New("disk full")))
+	errLgr.Errorf("failed")
+	expectedCaller := callerLine(-1)
+	errLgr.Errorf("failed again")
+
+	lines := splitLines(tw.contents)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(lines))
+	}
+
+	got := decodeErrorField(t, lines[0])
+	if len(got.Stack) == 0 {
+		t.Fatalf("expected a stack, got none")
+	}
+
+	expectedTop := "learn-go-pockets/logger/pocketlog_test.TestLogger_WithErrorStack " + expectedCaller
+	if got.Stack[0] != expectedTop {
+		t.Errorf("expected the stack to start with %q, got %q", expectedTop, got.Stack[0])
+	}
+
+	// each entry has the stack of its own call
+	if again := decodeErrorField(t, lines[1]); again.Stack[0] == got.Stack[0] {
+		t.Errorf("expected a different stack for the second entry, got %q", again.Stack[0])
+	}
+}
+
+// decodeErrorField returns the error field of a single JSON entry.
+func decodeErrorField(t *testing.T, line string) errorTree {
+	t.Helper()
+
+	var entry struct {
+		Error errorTree `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("invalid JSON log: %v", err)
+	}
+
+	return entry.Error
+}
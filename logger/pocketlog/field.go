@@ -0,0 +1,198 @@
+package pocketlog
+
+import (
+	"bytes"
+	"time"
+)
+
+// Field is a key/value pair added to every entry written by a Logger.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// fieldPrefix is prepended to a field key that collides with a key
+// written by the logger itself, so that a field can never shadow it.
+const fieldPrefix = "fields."
+
+// reservedKeys lists the keys of the entry written by the logger.
+var reservedKeys = map[string]bool{
+	"time":    true,
+	"level":   true,
+	"message": true,
+	"logger":  true,
+	"caller":  true,
+	"func":    true,
+}
+
+// fieldKey returns the key under which a field is written.
+func fieldKey(key string) string {
+	if reservedKeys[key] {
+		return fieldPrefix + key
+	}
+	return key
+}
+
+// mergeFields returns a new slice holding base followed by extra.
+// A key present in both keeps its original position but takes the most
+// recent value, so keys are always written in the order they were first seen.
+func mergeFields(base, extra []Field) []Field {
+	merged := make([]Field, len(base), len(base)+len(extra))
+	copy(merged, base)
+
+	for _, f := range extra {
+		if i := indexField(merged, f.Key); i >= 0 {
+			merged[i] = f
+			continue
+		}
+		merged = append(merged, f)
+	}
+
+	return merged
+}
+
+// indexField returns the position of the field named key, or -1.
+func indexField(fields []Field, key string) int {
+	for i, f := range fields {
+		if f.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Lazy is a value computed only when an entry holding it is written.
+// Use it, as the value of a field or as an argument of a message,
+// for values that are expensive to compute:
+//
+//	lgr.With(pocketlog.Field{Key: "stats", Value: pocketlog.Lazy(func() any { return shelf.Stats() })})
+//
+// The function is called once per written entry, and may be called concurrently.
+type Lazy func() any
+
+// LogValuer is implemented by types that choose how they are logged,
+// as the value of a field. What LogValue returns is logged instead,
+// such as Fields to log a struct as a group:
+//
+//	func (b Book) LogValue() any {
+//		return pocketlog.Fields{pocketlog.String("title", b.Title), pocketlog.Int("year", b.Year)}
+//	}
+type LogValuer interface {
+	LogValue() any
+}
+
+// maxResolveDepth bounds how many times a value is resolved, in case
+// a LogValuer or a Lazy keeps returning another one.
+const maxResolveDepth = 32
+
+// Fields is a group of fields, as the value of a field created by Group.
+// The JSON encoder writes it as a nested object, other encoders prefix
+// the keys of its fields with the key of the group and a dot.
+type Fields []Field
+
+// MarshalJSON implements the json.Marshaler interface.
+func (fs Fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	appendJSONObject(&buf, fs)
+	return buf.Bytes(), nil
+}
+
+// String returns a field holding a string.
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns a field holding an integer.
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns a field holding a boolean.
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration returns a field holding a duration. The JSON encoder writes it as
+// a number of nanoseconds, other encoders as text, such as 1.5s.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time returns a field holding a time, written in the RFC 3339 format
+// with nanoseconds.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Any returns a field holding any value. Strings, booleans, numbers, durations,
+// times, errors and Fields are written natively, other values are marshalled
+// to JSON, or printed if they can't be.
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Group returns a field holding other fields, such as the attributes of a request:
+//
+//	lgr.With(pocketlog.Group("request", pocketlog.String("method", "GET"), pocketlog.Int("size", 512)))
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Value: Fields(fields)}
+}
+
+// resolveFields returns the fields, where Lazy values are computed,
+// and LogValuer values replaced, including in groups, and whether one of them
+// was resolved. The fields are only copied if so.
+func resolveFields(fields []Field) ([]Field, bool) {
+	resolved := fields
+	copied := false
+	for i, f := range fields {
+		value, ok := resolveValue(f.Value)
+		if !ok {
+			continue
+		}
+
+		// fields are shared with the logger, copy them before editing them
+		if !copied {
+			resolved = append([]Field(nil), fields...)
+			copied = true
+		}
+		resolved[i].Value = value
+	}
+
+	return resolved, copied
+}
+
+// resolveValue returns what should be logged for the value of a field,
+// and whether it differs from the value.
+func resolveValue(value any) (any, bool) {
+	resolved := false
+	for range maxResolveDepth {
+		switch v := value.(type) {
+		case Lazy:
+			value = v()
+		case LogValuer:
+			value = v.LogValue()
+		case Fields:
+			if group, ok := resolveFields(v); ok {
+				return Fields(group), true
+			}
+			return value, resolved
+		default:
+			return value, resolved
+		}
+		resolved = true
+	}
+
+	return value, resolved
+}
+
+// walkFields calls fn with the key and value of every field, where the fields
+// of groups are flattened: their keys are prefixed by the key of their group.
+func walkFields(prefix string, fields []Field, fn func(key string, value any)) {
+	for _, f := range fields {
+		if group, ok := f.Value.(Fields); ok {
+			walkFields(prefix+f.Key+".", group, fn)
+			continue
+		}
+		fn(prefix+f.Key, f.Value)
+	}
+}
@@ -0,0 +1,150 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"learn-go-pockets/logger/pocketlog"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// book chooses how it is logged.
+type book struct {
+	title string
+	year  int
+}
+
+// LogValue implements the pocketlog.LogValuer interface.
+func (b book) LogValue() any {
+	return pocketlog.Fields{pocketlog.String("title", b.title), pocketlog.Int("year", b.year)}
+}
+
+func ExampleGroup() {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithClock(fixedClock))
+	lgr.With(
+		pocketlog.Group("request", pocketlog.String("method", "GET"), pocketlog.Int("size", 512)),
+		pocketlog.Any("book", book{title: "Dune", year: 1965}),
+	).Infof("Served")
+	// Output:
+	// {"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"Served","request":{"method":"GET","size":512},"book":{"title":"Dune","year":1965}}
+}
+
+func TestTypedFields(t *testing.T) {
+	fields := []pocketlog.Field{
+		pocketlog.String("title", "Dune"),
+		pocketlog.Int("pages", 412),
+		pocketlog.Bool("available", true),
+		pocketlog.Duration("loan", 36*time.Hour),
+		pocketlog.Time("returned", fixedTime),
+		pocketlog.Any("rating", 4.5),
+		pocketlog.Any("tiny", 1e-7),
+		pocketlog.Group("author",
+			pocketlog.String("name", "Frank Herbert"),
+			pocketlog.Group("born", pocketlog.Int("year", 1920)),
+		),
+		pocketlog.Group("empty"),
+	}
+
+	tests := map[string]struct {
+		encoder  pocketlog.Encoder
+		expected string
+	}{
+		"json": {
+			encoder: pocketlog.JSONEncoder{},
+			expected: `{"time":"` + fixedTimeText + `","level":"info","message":"Found",` +
+				`"title":"Dune","pages":412,"available":true,"loan":129600000000000,"returned":"` + fixedTimeText + `",` +
+				`"rating":4.5,"tiny":1e-7,"author":{"name":"Frank Herbert","born":{"year":1920}},"empty":{}}` + "\n",
+		},
+		"logfmt": {
+			encoder: pocketlog.LogfmtEncoder{},
+			expected: `time=` + fixedTimeText + ` level=info message=Found ` +
+				`title=Dune pages=412 available=true loan=36h0m0s returned=` + fixedTimeText + ` ` +
+				`rating=4.5 tiny=1e-07 author.name="Frank Herbert" author.born.year=1920` + "\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			entry := pocketlog.Entry{Time: fixedTime, Level: pocketlog.LevelInfo, Message: "Found", Fields: fields}
+			if err := tc.encoder.Encode(&buf, entry); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestLogger_ResolvesFieldsInGroups(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithRedactedKeys("password"),
+	)
+
+	calls := 0
+	lgr.With(pocketlog.Group("user",
+		pocketlog.String("name", "paul"),
+		pocketlog.String("password", "spice"),
+		pocketlog.Any("favourite", book{title: "Dune", year: 1965}),
+		pocketlog.Any("loans", pocketlog.Lazy(func() any { calls++; return calls })),
+	)).Infof("Logged in")
+
+	expected := `{"time":"` + fixedTimeText + `","level":"info","message":"Logged in",` +
+		`"user":{"name":"paul","password":"[REDACTED]","favourite":{"title":"Dune","year":1965},"loans":1}}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %s, got %s", expected, tw.contents)
+	}
+}
+
+func TestWithSlogHandler_Groups(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithSlogHandler(h))
+
+	lgr.With(pocketlog.Group("book", pocketlog.String("title", "Dune"), pocketlog.Int("year", 1965))).Infof("Found")
+
+	expected := `{"level":"INFO","msg":"Found","book":{"title":"Dune","year":1965}}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
+
+func BenchmarkJSONEncoder_Fields(b *testing.B) {
+	tests := map[string][]pocketlog.Field{
+		"typed": {
+			pocketlog.Group("book", pocketlog.String("title", "Dune"), pocketlog.Int("pages", 412)),
+			pocketlog.Duration("loan", 36*time.Hour),
+		},
+		"reflected": {
+			pocketlog.Any("book", struct {
+				Title string `json:"title"`
+				Pages int    `json:"pages"`
+			}{Title: "Dune", Pages: 412}),
+			pocketlog.Any("loan", 36*time.Hour),
+		},
+	}
+
+	for name, fields := range tests {
+		b.Run(name, func(b *testing.B) {
+			var buf bytes.Buffer
+			entry := pocketlog.Entry{Time: fixedTime, Level: pocketlog.LevelInfo, Message: "Found", Fields: fields}
+			b.ReportAllocs()
+			for b.Loop() {
+				buf.Reset()
+				_ = pocketlog.JSONEncoder{}.Encode(&buf, entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package pocketlog
+
+import "sync"
+
+// backfillKey is the key of the field marking the entries written by the flight recorder.
+const backfillKey = "backfill"
+
+// flightRecorder keeps the most recent entries below the threshold,
+// to write them when an error occurs. It is shared with child loggers.
+type flightRecorder struct {
+	mu sync.Mutex
+	// entries is a ring buffer, where next is the position of the oldest
+	// entry once it is full.
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// newFlightRecorder returns a flight recorder keeping at most size entries.
+func newFlightRecorder(size int) *flightRecorder {
+	return &flightRecorder{entries: make([]Entry, size)}
+}
+
+// record keeps the entry, replacing the oldest one if the recorder is full.
+func (f *flightRecorder) record(e Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.entries[f.next] = e
+	f.next++
+	if f.next == len(f.entries) {
+		f.next = 0
+		f.full = true
+	}
+}
+
+// drain returns the recorded entries, from the oldest to the newest, and forgets them.
+// The nil flight recorder holds no entry.
+func (f *flightRecorder) drain() []Entry {
+	if f == nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var drained []Entry
+	if f.full {
+		drained = append(drained, f.entries[f.next:]...)
+	}
+	drained = append(drained, f.entries[:f.next]...)
+
+	clear(f.entries)
+	f.next = 0
+	f.full = false
+
+	return drained
+}
+
+// discards tells whether an entry at the given level can be discarded
+// right away: it is below the threshold, and no flight recorder keeps it.
+// The entry is then counted as suppressed.
+func (l *Logger) discards(lvl Level) bool {
+	if l.flight != nil || l.Enabled(lvl) {
+		return false
+	}
+
+	l.counts.suppress(lvl)
+	return true
+}
+
+// writeBackfill writes the entries kept by the flight recorder,
+// each marked by a backfill field.
+func (l *Logger) writeBackfill() {
+	for _, e := range l.flight.drain() {
+		e.Fields = mergeFields(e.Fields, []Field{{Key: backfillKey, Value: true}})
+		l.writeEntry(e)
+	}
+}
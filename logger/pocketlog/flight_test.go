@@ -0,0 +1,97 @@
+package pocketlog_test
+
+import (
+	"io"
+	"learn-go-pockets/logger/pocketlog"
+	"os"
+	"testing"
+)
+
+func ExampleWithFlightRecorder() {
+	lgr := pocketlog.New(pocketlog.LevelWarn,
+		pocketlog.WithOutput(os.Stdout),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithEncoder(pocketlog.TextEncoder{}),
+		pocketlog.WithFlightRecorder(2),
+	)
+
+	lgr.Infof("Opening shelf")
+	lgr.Debugf("Reading book 1")
+	lgr.Debugf("Reading book 2")
+	lgr.Errorf("Book 2 is unreadable")
+	lgr.Errorf("Giving up")
+	// Output:
+	// 2025-03-14T15:09:26.535897932Z [debug] Reading book 1  backfill=true
+	// 2025-03-14T15:09:26.535897932Z [debug] Reading book 2  backfill=true
+	// 2025-03-14T15:09:26.535897932Z [error] Book 2 is unreadable
+	// 2025-03-14T15:09:26.535897932Z [error] Giving up
+}
+
+func TestLogger_WithFlightRecorder(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithFlightRecorder(3),
+	)
+	child := lgr.With(pocketlog.Field{Key: "shelf", Value: "sci-fi"})
+
+	lgr.Tracef("trace %d", 1)
+	child.Debugf("debug %d", 2)
+	lgr.Infof("info %d", 3)
+	lgr.Debugf("debug %d", 4)
+	lgr.Tracef("trace %d", 5)
+	child.Errorf("error %d", 6)
+	lgr.Errorf("error %d", 7)
+
+	expected := []string{
+		`time=` + fixedTimeText + ` level=info message="info 3"`,
+		`time=` + fixedTimeText + ` level=debug message="debug 2" shelf=sci-fi backfill=true`,
+		`time=` + fixedTimeText + ` level=debug message="debug 4" backfill=true`,
+		`time=` + fixedTimeText + ` level=trace message="trace 5" backfill=true`,
+		`time=` + fixedTimeText + ` level=error message="error 6" shelf=sci-fi`,
+		`time=` + fixedTimeText + ` level=error message="error 7"`,
+	}
+
+	lines := splitLines(tw.contents)
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d: %q", len(expected), len(lines), lines)
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("line %d: expected %s, got %s", i, expected[i], line)
+		}
+	}
+}
+
+func TestLogger_WithFlightRecorderOnFatal(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelError,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithExitFunc(func(int) {}),
+		pocketlog.WithFlightRecorder(1),
+	)
+
+	lgr.Warnf("warn")
+	lgr.Fatalf("fatal")
+
+	expected := `time=` + fixedTimeText + ` level=warn message=warn backfill=true` + "\n" +
+		`time=` + fixedTimeText + ` level=fatal message=fatal` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %s, got %s", expected, tw.contents)
+	}
+}
+
+func TestLogger_WithFlightRecorderAllocations(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(io.Discard),
+		pocketlog.WithFlightRecorder(0),
+	)
+
+	if got := testing.AllocsPerRun(100, func() { lgr.Debugf("%d books", 42) }); got != 0 {
+		t.Errorf("expected a disabled flight recorder not to allocate, got %v allocations", got)
+	}
+}
@@ -0,0 +1,60 @@
+package pocketlog
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// levelPayload is the JSON body written by the level handler.
+type levelPayload struct {
+	Level Level `json:"level"`
+}
+
+// levelRequest is the JSON body read by the level handler. The level is a
+// pointer to tell a missing key apart from the zero Level.
+type levelRequest struct {
+	Level *Level `json:"level"`
+}
+
+// errorPayload is the JSON body written by the level handler on failure.
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+// LevelHandler returns an http.Handler to operate the threshold of the logger
+// while it runs. A GET request reports the current level, and a PUT request
+// changes it. Both use a JSON body such as {"level":"debug"}: a PUT request
+// without a level is rejected.
+//
+// The handler doesn't authenticate requests: mount it on an admin endpoint.
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var payload levelRequest
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				writeJSON(w, http.StatusBadRequest, errorPayload{Error: err.Error()})
+				return
+			}
+			if payload.Level == nil {
+				writeJSON(w, http.StatusBadRequest, errorPayload{Error: "missing level"})
+				return
+			}
+			l.SetLevel(*payload.Level)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeJSON(w, http.StatusMethodNotAllowed, errorPayload{Error: "method not allowed"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, levelPayload{Level: l.Level()})
+	})
+}
+
+// writeJSON writes the payload as the JSON body of the response.
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,91 @@
+package pocketlog_test
+
+import (
+	"learn-go-pockets/logger/pocketlog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger_LevelHandler(t *testing.T) {
+	tests := map[string]struct {
+		method         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedLevel  pocketlog.Level
+	}{
+		"get": {
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"level":"info"}`,
+			expectedLevel:  pocketlog.LevelInfo,
+		},
+		"put": {
+			method:         http.MethodPut,
+			body:           `{"level":"debug"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"level":"debug"}`,
+			expectedLevel:  pocketlog.LevelDebug,
+		},
+		"put unknown level": {
+			method:         http.MethodPut,
+			body:           `{"level":"chatty"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"pocketlog: unknown level: \"chatty\""}`,
+			expectedLevel:  pocketlog.LevelInfo,
+		},
+		"put missing level": {
+			method:         http.MethodPut,
+			body:           `{"lvl":"error"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"missing level"}`,
+			expectedLevel:  pocketlog.LevelInfo,
+		},
+		"put empty object": {
+			method:         http.MethodPut,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"missing level"}`,
+			expectedLevel:  pocketlog.LevelInfo,
+		},
+		"put invalid JSON": {
+			method:         http.MethodPut,
+			body:           `debug`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"invalid character 'd' looking for beginning of value"}`,
+			expectedLevel:  pocketlog.LevelInfo,
+		},
+		"post": {
+			method:         http.MethodPost,
+			body:           `{"level":"debug"}`,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   `{"error":"method not allowed"}`,
+			expectedLevel:  pocketlog.LevelInfo,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testedLogger := pocketlog.New(pocketlog.LevelInfo)
+
+			req := httptest.NewRequest(tc.method, "/admin/log/level", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+
+			testedLogger.LevelHandler().ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+
+			if got := strings.TrimSpace(rec.Body.String()); got != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, got)
+			}
+
+			if testedLogger.Level() != tc.expectedLevel {
+				t.Errorf("expected level %v, got %v", tc.expectedLevel, testedLogger.Level())
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package pocketlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// JournalSocket is the socket journald reads native messages from.
+const JournalSocket = "/run/systemd/journal/socket"
+
+// JournalEncoder writes each entry in the native protocol of the systemd journal,
+// as a list of variables: MESSAGE, PRIORITY, SYSLOG_IDENTIFIER, LOGGER for named
+// loggers, the CODE_FILE, CODE_LINE and CODE_FUNC of the caller if known, then the
+// fields, whose keys are turned into valid variable names, e.g. "request.id"
+// becomes REQUEST_ID.
+// Use it with DialJournal.
+type JournalEncoder struct {
+	// Identifier is the SYSLOG_IDENTIFIER of the entries,
+	// and defaults to the name of the executable.
+	Identifier string
+}
+
+// Encode implements the Encoder interface.
+func (enc JournalEncoder) Encode(buf *bytes.Buffer, e Entry) error {
+	appendJournalVar(buf, "MESSAGE", e.Message)
+	appendJournalVar(buf, "PRIORITY", strconv.Itoa(e.Level.SyslogSeverity()))
+	appendJournalVar(buf, "SYSLOG_IDENTIFIER", orDefault(enc.Identifier, appName))
+
+	if e.Logger != "" {
+		appendJournalVar(buf, "LOGGER", e.Logger)
+	}
+
+	if e.Caller != "" {
+		file, line := splitCaller(e.Caller)
+		appendJournalVar(buf, "CODE_FILE", file)
+		appendJournalVar(buf, "CODE_LINE", line)
+		appendJournalVar(buf, "CODE_FUNC", e.Func)
+	}
+
+	walkFields("", e.Fields, func(key string, value any) {
+		appendJournalVar(buf, journalName(key), valueText(value))
+	})
+
+	return nil
+}
+
+// DialJournal connects to the local journald socket.
+func DialJournal() (*SyslogWriter, error) {
+	return DialSyslog("unixgram", JournalSocket)
+}
+
+// appendJournalVar writes a variable of the native journal protocol.
+// Values spanning several lines are written in the binary form,
+// prefixed by their length as a little-endian 64-bit integer.
+func appendJournalVar(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+
+	if !strings.ContainsRune(value, '\n') {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(value))))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalName returns a valid journal variable name for key: at most 64 uppercase
+// letters, digits and underscores, starting with a letter.
+func journalName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	if name == "" || name[0] < 'A' || name[0] > 'Z' {
+		name = "F_" + strings.TrimLeft(name, "_")
+	}
+
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return name
+}
@@ -0,0 +1,131 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"learn-go-pockets/logger/pocketlog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalEncoder(t *testing.T) {
+	entry := pocketlog.Entry{
+		Time:    fixedTime,
+		Level:   pocketlog.LevelWarn,
+		Message: "Shelf is almost full",
+		Caller:  "bookworms/main.go:12",
+		Func:    "main.main",
+		Fields: []pocketlog.Field{
+			{Key: "request.id", Value: "f3a9"},
+			{Key: "_private", Value: 1},
+			{Key: "2fa", Value: true},
+			{Key: "notes", Value: "two\nlines"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (pocketlog.JournalEncoder{Identifier: "bookworms"}).Encode(&buf, entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "MESSAGE=Shelf is almost full\n" +
+		"PRIORITY=4\n" +
+		"SYSLOG_IDENTIFIER=bookworms\n" +
+		"CODE_FILE=bookworms/main.go\n" +
+		"CODE_LINE=12\n" +
+		"CODE_FUNC=main.main\n" +
+		"REQUEST_ID=f3a9\n" +
+		"F_PRIVATE=1\n" +
+		"F_2FA=true\n" +
+		"NOTES\n\x09\x00\x00\x00\x00\x00\x00\x00two\nlines\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%q\ngot\n%q", expected, buf.String())
+	}
+}
+
+func TestDialSyslog_Unixgram(t *testing.T) {
+	// socket paths are short, and t.TempDir may be too long
+	dir, err := os.MkdirTemp("", "pl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("can't listen on a unix datagram socket: %v", err)
+	}
+	defer conn.Close()
+
+	w, err := pocketlog.DialSyslog("unixgram", socket)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(w),
+		pocketlog.WithEncoder(pocketlog.JournalEncoder{Identifier: "bookworms"}),
+	)
+	lgr.Errorf("Shelf not found")
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	packet := make([]byte, 1024)
+	n, err := conn.Read(packet)
+	if err != nil {
+		t.Fatalf("can't read message: %v", err)
+	}
+
+	expected := "MESSAGE=Shelf not found\nPRIORITY=3\nSYSLOG_IDENTIFIER=bookworms\n"
+	if string(packet[:n]) != expected {
+		t.Errorf("expected %q, got %q", expected, packet[:n])
+	}
+}
+
+func TestDialSyslog_UnixgramRestart(t *testing.T) {
+	dir, err := os.MkdirTemp("", "pl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "journal.sock")
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	conn, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Skipf("can't listen on a unix datagram socket: %v", err)
+	}
+
+	w, err := pocketlog.DialSyslog("unixgram", socket)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	// journald restarts, and binds a new socket
+	_ = conn.Close()
+	_ = os.Remove(socket)
+	conn, err = net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := w.Write([]byte("MESSAGE=back\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	packet := make([]byte, 1024)
+	n, err := conn.Read(packet)
+	if err != nil {
+		t.Fatalf("can't read message: %v", err)
+	}
+
+	if string(packet[:n]) != "MESSAGE=back\n" {
+		t.Errorf("expected the message on the new socket, got %q", packet[:n])
+	}
+}
@@ -0,0 +1,237 @@
+package pocketlog
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// JSONEncoder writes each entry as a JSON object on its own line.
+// This is the default encoder. Its zero value writes the default keys,
+// and the keys can be renamed to follow the schema a log pipeline expects,
+// as GCPEncoder, ECSEncoder and DatadogEncoder do.
+type JSONEncoder struct {
+	// Keys renames the keys written by the encoder.
+	Keys JSONKeys
+	// LevelText returns the text of a level, and defaults to Level.String.
+	LevelText func(Level) string
+	// SourceLocation, if set, is the key of an object holding the file,
+	// line and function of the caller, written instead of the Caller
+	// and Func keys.
+	SourceLocation string
+}
+
+// JSONKeys holds the keys of the values a JSONEncoder writes for every entry.
+// Keys left empty take their default name, which is the name of the field
+// in lowercase, e.g. "message" for Message.
+type JSONKeys struct {
+	Time    string
+	Level   string
+	Message string
+	Logger  string
+	Caller  string
+	Func    string
+	// Line, if set, is the key of the line of the caller,
+	// which is then left out of the Caller key.
+	Line string
+}
+
+// withDefaults returns the keys, where the empty ones take their default name.
+func (k JSONKeys) withDefaults() JSONKeys {
+	k.Time = cmp.Or(k.Time, "time")
+	k.Level = cmp.Or(k.Level, "level")
+	k.Message = cmp.Or(k.Message, "message")
+	k.Logger = cmp.Or(k.Logger, "logger")
+	k.Caller = cmp.Or(k.Caller, "caller")
+	k.Func = cmp.Or(k.Func, "func")
+	return k
+}
+
+// reserved tells whether key is written by the encoder,
+// in which case a field can't be written under it.
+func (enc JSONEncoder) reserved(keys JSONKeys, key string) bool {
+	switch key {
+	case keys.Time, keys.Level, keys.Message, keys.Logger, keys.Caller, keys.Func:
+		return true
+	}
+	return key != "" && (key == keys.Line || key == enc.SourceLocation)
+}
+
+// Encode implements the Encoder interface.
+func (enc JSONEncoder) Encode(buf *bytes.Buffer, e Entry) error {
+	keys := enc.Keys.withDefaults()
+
+	buf.WriteByte('{')
+	appendJSONString(buf, keys.Time)
+	buf.WriteByte(':')
+	switch {
+	case e.timeFormat == TimeFormatUnixMilli:
+		buf.Write(e.appendTime(buf.AvailableBuffer()))
+	case e.plainTime():
+		buf.WriteByte('"')
+		buf.Write(e.appendTime(buf.AvailableBuffer()))
+		buf.WriteByte('"')
+	default:
+		appendJSONString(buf, string(e.appendTime(nil)))
+	}
+
+	levelText := e.Level.String()
+	if enc.LevelText != nil {
+		levelText = enc.LevelText(e.Level)
+	}
+	appendJSONPair(buf, keys.Level, levelText)
+	appendJSONPair(buf, keys.Message, e.Message)
+
+	if e.Logger != "" {
+		appendJSONPair(buf, keys.Logger, e.Logger)
+	}
+
+	if e.Caller != "" {
+		enc.appendCaller(buf, keys, e)
+	}
+
+	for _, f := range e.Fields {
+		key := f.Key
+		if enc.reserved(keys, key) {
+			key = fieldPrefix + key
+		}
+
+		buf.WriteByte(',')
+		appendJSONString(buf, key)
+		buf.WriteByte(':')
+		appendJSONValue(buf, f.Value)
+	}
+
+	buf.WriteString("}\n")
+	return nil
+}
+
+// appendCaller writes the caller of the entry, and its function.
+func (enc JSONEncoder) appendCaller(buf *bytes.Buffer, keys JSONKeys, e Entry) {
+	file, line := splitCaller(e.Caller)
+
+	if enc.SourceLocation != "" {
+		buf.WriteByte(',')
+		appendJSONString(buf, enc.SourceLocation)
+		buf.WriteString(`:{"file":`)
+		appendJSONString(buf, file)
+		buf.WriteString(`,"line":`)
+		appendJSONString(buf, line)
+		buf.WriteString(`,"function":`)
+		appendJSONString(buf, e.Func)
+		buf.WriteByte('}')
+		return
+	}
+
+	if keys.Line == "" {
+		appendJSONPair(buf, keys.Caller, e.Caller)
+	} else {
+		appendJSONPair(buf, keys.Caller, file)
+		buf.WriteByte(',')
+		appendJSONString(buf, keys.Line)
+		buf.WriteByte(':')
+		buf.WriteString(cmp.Or(line, "0"))
+	}
+	appendJSONPair(buf, keys.Func, e.Func)
+}
+
+// appendJSONPair writes a comma, then the key and its string value.
+func appendJSONPair(buf *bytes.Buffer, key, value string) {
+	buf.WriteByte(',')
+	appendJSONString(buf, key)
+	buf.WriteByte(':')
+	appendJSONString(buf, value)
+}
+
+// appendJSONValue writes v to buf as a JSON value.
+// Common types are written natively, other values are marshalled.
+func appendJSONValue(buf *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case string:
+		appendJSONString(buf, v)
+		return
+	case bool:
+		buf.Write(strconv.AppendBool(buf.AvailableBuffer(), v))
+		return
+	case int:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(v), 10))
+		return
+	case int64:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), v, 10))
+		return
+	case uint64:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), v, 10))
+		return
+	case float64:
+		if appendJSONFloat(buf, v) {
+			return
+		}
+	case time.Duration:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(v), 10))
+		return
+	case time.Time:
+		buf.WriteByte('"')
+		buf.Write(v.AppendFormat(buf.AvailableBuffer(), time.RFC3339Nano))
+		buf.WriteByte('"')
+		return
+	case Fields:
+		appendJSONObject(buf, v)
+		return
+	case error:
+		// errors usually have no exported fields, and would marshal to {}
+		appendJSONString(buf, v.Error())
+		return
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		// fallback to the printed value if it can't be marshalled
+		appendJSONString(buf, fmt.Sprint(v))
+		return
+	}
+
+	buf.Write(b)
+}
+
+// appendJSONFloat writes f as encoding/json does, and tells whether it could:
+// NaN and infinities have no JSON representation.
+func appendJSONFloat(buf *bytes.Buffer, f float64) bool {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return false
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	b := strconv.AppendFloat(buf.AvailableBuffer(), f, format, -1, 64)
+	if format == 'e' {
+		// turn e-09 into e-9, as encoding/json does
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+
+	buf.Write(b)
+	return true
+}
+
+// appendJSONObject writes the fields of a group to buf as a JSON object.
+func appendJSONObject(buf *bytes.Buffer, fields Fields) {
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		appendJSONString(buf, f.Key)
+		buf.WriteByte(':')
+		appendJSONValue(buf, f.Value)
+	}
+	buf.WriteByte('}')
+}
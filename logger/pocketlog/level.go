@@ -1,16 +1,91 @@
 package pocketlog
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // Level represents an availabale logging level.
 type Level byte
 
 const (
-	// LevelDebug represents the lowest level of log,
+	// LevelTrace represents the lowest level of log,
+	// used to follow the finest details of a process.
+	LevelTrace Level = iota
+	// LevelDebug represents a logging level
 	// mostly used for debugging purposes.
-	LevelDebug Level = iota
+	LevelDebug
 	// LevelInfo represents a logging level that contains
 	// information deemed valuable.
 	LevelInfo
-	// LevelError represents the highest logging level,
+	// LevelWarn represents a logging level for unexpected
+	// situations the process can recover from.
+	LevelWarn
+	// LevelError represents a logging level
 	// only to be used to trace errors.
 	LevelError
+	// LevelFatal represents the highest logging level,
+	// for errors after which the process exits.
+	LevelFatal
 )
+
+// ErrUnknownLevel is returned when parsing the name of a level that doesn't exist.
+var ErrUnknownLevel = errors.New("pocketlog: unknown level")
+
+// levelNames holds the name of each level, as written in entries.
+var levelNames = [...]string{
+	LevelTrace: "trace",
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+	LevelFatal: "fatal",
+}
+
+// String returns the lowercase name of the level, as written in entries.
+func (lvl Level) String() string {
+	if int(lvl) < len(levelNames) {
+		return levelNames[lvl]
+	}
+	return fmt.Sprintf("level(%d)", lvl)
+}
+
+// ParseLevel returns the level with the given name, regardless of its case.
+// "warning" is accepted as an alias of "warn".
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		return LevelWarn, nil
+	}
+
+	for lvl, lvlName := range levelNames {
+		if name == lvlName {
+			return Level(lvl), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrUnknownLevel, name)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface,
+// so that a level is written by its name in JSON or other text formats.
+func (lvl Level) MarshalText() ([]byte, error) {
+	if int(lvl) >= len(levelNames) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownLevel, lvl)
+	}
+	return []byte(levelNames[lvl]), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface,
+// so that a level can be read from a flag (see flag.TextVar),
+// an environment variable or a configuration file.
+func (lvl *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*lvl = parsed
+	return nil
+}
@@ -0,0 +1,99 @@
+package pocketlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"learn-go-pockets/logger/pocketlog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]struct {
+		name     string
+		expected pocketlog.Level
+		err      error
+	}{
+		"trace":                {name: "trace", expected: pocketlog.LevelTrace},
+		"debug":                {name: "debug", expected: pocketlog.LevelDebug},
+		"info":                 {name: "info", expected: pocketlog.LevelInfo},
+		"warn":                 {name: "warn", expected: pocketlog.LevelWarn},
+		"warning alias":        {name: "warning", expected: pocketlog.LevelWarn},
+		"error":                {name: "error", expected: pocketlog.LevelError},
+		"fatal":                {name: "fatal", expected: pocketlog.LevelFatal},
+		"upper case":           {name: "ERROR", expected: pocketlog.LevelError},
+		"surrounded by spaces": {name: " info\n", expected: pocketlog.LevelInfo},
+		"unknown":              {name: "verbose", err: pocketlog.ErrUnknownLevel},
+		"empty":                {name: "", err: pocketlog.ErrUnknownLevel},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := pocketlog.ParseLevel(tc.name)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLevel_String(t *testing.T) {
+	if got := pocketlog.LevelWarn.String(); got != "warn" {
+		t.Errorf("expected warn, got %s", got)
+	}
+
+	if got := pocketlog.Level(42).String(); got != "level(42)" {
+		t.Errorf("expected level(42), got %s", got)
+	}
+}
+
+func TestLevel_JSON(t *testing.T) {
+	type config struct {
+		Level pocketlog.Level `json:"level"`
+	}
+
+	b, err := json.Marshal(config{Level: pocketlog.LevelWarn})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(b) != `{"level":"warn"}` {
+		t.Errorf(`expected {"level":"warn"}, got %s`, b)
+	}
+
+	var got config
+	if err := json.Unmarshal([]byte(`{"level":"Fatal"}`), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Level != pocketlog.LevelFatal {
+		t.Errorf("expected fatal, got %v", got.Level)
+	}
+
+	if err := json.Unmarshal([]byte(`{"level":"loud"}`), &got); !errors.Is(err, pocketlog.ErrUnknownLevel) {
+		t.Errorf("expected ErrUnknownLevel, got %v", err)
+	}
+
+	if _, err := json.Marshal(config{Level: pocketlog.Level(42)}); !errors.Is(err, pocketlog.ErrUnknownLevel) {
+		t.Errorf("expected ErrUnknownLevel, got %v", err)
+	}
+}
+
+func TestLevel_Flag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+	var lvl pocketlog.Level
+	fs.TextVar(&lvl, "level", pocketlog.LevelInfo, "logging level")
+
+	if err := fs.Parse([]string{"-level", "debug"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if lvl != pocketlog.LevelDebug {
+		t.Errorf("expected debug, got %v", lvl)
+	}
+}
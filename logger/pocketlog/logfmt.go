@@ -0,0 +1,114 @@
+package pocketlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LogfmtEncoder writes each entry as a logfmt line,
+// a sequence of space-separated key=value pairs.
+type LogfmtEncoder struct{}
+
+// Encode implements the Encoder interface.
+func (LogfmtEncoder) Encode(buf *bytes.Buffer, e Entry) error {
+	buf.WriteString("time=")
+	if e.plainTime() {
+		buf.Write(e.appendTime(buf.AvailableBuffer()))
+	} else {
+		appendLogfmtValue(buf, string(e.appendTime(nil)))
+	}
+	buf.WriteString(" level=")
+	buf.WriteString(e.Level.String())
+	buf.WriteString(" message=")
+	appendLogfmtValue(buf, e.Message)
+
+	if e.Logger != "" {
+		buf.WriteString(" logger=")
+		appendLogfmtValue(buf, e.Logger)
+	}
+
+	if e.Caller != "" {
+		buf.WriteString(" caller=")
+		appendLogfmtValue(buf, e.Caller)
+		buf.WriteString(" func=")
+		appendLogfmtValue(buf, e.Func)
+	}
+
+	walkFields("", e.Fields, func(key string, value any) {
+		buf.WriteByte(' ')
+		buf.WriteString(logfmtKey(fieldKey(key)))
+		buf.WriteByte('=')
+		appendLogfmtValue(buf, valueText(value))
+	})
+
+	buf.WriteByte('\n')
+	return nil
+}
+
+// logfmtKey replaces the characters a logfmt key can't hold with underscores.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// appendLogfmtValue writes s to buf, quoted and escaped only if needed.
+func appendLogfmtValue(buf *bytes.Buffer, s string) {
+	if !needsQuoting(s) {
+		buf.WriteString(s)
+		return
+	}
+
+	buf.Write(strconv.AppendQuote(buf.AvailableBuffer(), s))
+}
+
+// needsQuoting reports whether s must be quoted to be read back as a single value.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f || r == utf8.RuneError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueText returns the text representation of a field value:
+// strings as they are, JSON for composite values.
+func valueText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
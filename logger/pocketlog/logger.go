@@ -1,80 +1,278 @@
 package pocketlog
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"os"
-	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // Logger is used to log information.
+// It is safe for concurrent use: each entry is written to an output
+// in a single call, and never interleaved with another.
 type Logger struct {
-	threshold Level
-	output    io.Writer
+	// threshold holds the Level, and is shared with child loggers.
+	threshold *atomic.Uint32
+	// name is set by Named, and overrides hold the thresholds of named loggers,
+	// shared with child loggers.
+	name      string
+	overrides *levelOverrides
+	// sinks are the outputs entries are written to. The first one is
+	// configured by WithOutput and WithEncoder, the others by WithSink.
+	// outputSet tells whether the first one was configured at all.
+	sinks      []*sink
+	outputSet  bool
+	fields     []Field
+	clock      func() time.Time
+	timeFormat string
+	exit       func(code int)
+	// contextKeys are the context values added to entries as fields.
+	contextKeys []contextField
+	handler     slog.Handler
+	// caller tells whether entries hold their caller, found callerSkip
+	// frames above the logging method.
+	caller     bool
+	callerSkip int
+	// errorStack tells whether errors recorded with Err hold the stack of the logging code.
+	errorStack bool
+	// sampler is shared with child loggers.
+	sampler  *sampler
+	redactor *redactor
+	// flight keeps the entries below the threshold, and is shared with child loggers.
+	flight *flightRecorder
+	// errorHandler is called with the errors of the sinks, and fallback
+	// takes over the outputs that fail.
+	errorHandler func(err error)
+	fallback     *fallback
+	// counts is shared with child loggers.
+	counts *levelCounts
 }
 
-// LogEntry is the JSON structure for each log message.
+// LogEntry is the JSON structure for each log message written by the JSONEncoder.
+// Time is only decoded for layout-based time formats,
+// as TimeFormatUnixMilli writes it as a number.
 type LogEntry struct {
+	Time    string `json:"time,omitempty"`
 	Level   string `json:"level"`
 	Message string `json:"message"`
-	//Time    string `json:"time"`
+	Logger  string `json:"logger,omitempty"`
+	Caller  string `json:"caller,omitempty"`
+	Func    string `json:"func,omitempty"`
 }
 
 // New returns you a logger, ready to log at the required threshold.
 // Give it a list of configuration functions to tune it to your will.
-// The default output is Stdout.
+// The default output is Stdout, where entries are written as JSON,
+// timestamped with the current time in the TimeFormatRFC3339Nano format.
+// With WithSink, and neither WithOutput nor WithEncoder, there is no default output.
 func New(threshold Level, opts ...Option) *Logger {
-	lgr := &Logger{threshold: threshold, output: os.Stdout}
+	lgr := &Logger{
+		threshold:  &atomic.Uint32{},
+		overrides:  &levelOverrides{},
+		counts:     &levelCounts{},
+		sinks:      []*sink{{output: os.Stdout, encoder: JSONEncoder{}}},
+		clock:      time.Now,
+		timeFormat: TimeFormatRFC3339Nano,
+		exit:       os.Exit,
+		contextKeys: []contextField{
+			{field: "request_id", key: requestIDKey},
+			{field: "trace_id", key: traceIDKey},
+			{field: "span_id", key: spanIDKey},
+		},
+	}
+
+	lgr.threshold.Store(uint32(threshold))
 
 	for _, configFunc := range opts {
 		configFunc(lgr)
 	}
 
+	if !lgr.outputSet && len(lgr.sinks) > 1 {
+		// the sinks replace the default output
+		lgr.sinks = lgr.sinks[1:]
+	}
+
 	return lgr
 }
 
+// Level returns the current threshold of the logger,
+// or the one overriding it if the logger is named.
+func (l *Logger) Level() Level {
+	if lvl, ok := l.overrides.lookup(l.name); ok {
+		return lvl
+	}
+	return Level(l.threshold.Load())
+}
+
+// SetLevel changes the threshold of the logger, while it is in use.
+// As the threshold is shared, this also affects the loggers derived
+// from it with With, and the logger it was derived from.
+// The overrides of named loggers still apply, see SetLevelSpec.
+func (l *Logger) SetLevel(lvl Level) {
+	l.threshold.Store(uint32(lvl))
+}
+
+// Enabled tells whether an entry at the given level would be logged.
+// Use it to skip building values that are expensive to compute:
+//
+//	if lgr.Enabled(pocketlog.LevelDebug) {
+//		lgr.Debugf("shelves: %v", dumpShelves())
+//	}
+func (l *Logger) Enabled(lvl Level) bool {
+	return lvl >= l.Level()
+}
+
+// With returns a child logger that adds the given fields to every entry.
+// The child shares the outputs and threshold of its parent,
+// which is left untouched.
+func (l *Logger) With(fields ...Field) *Logger {
+	child := *l
+	child.fields = mergeFields(l.fields, fields)
+	return &child
+}
+
+// Tracef formats and prints a message if the log level is trace or higher.
+func (l *Logger) Tracef(format string, args ...any) {
+	if l.discards(LevelTrace) {
+		return
+	}
+
+	l.logf(context.Background(), LevelTrace, format, args...)
+}
+
 // Debugf formats and prints a message if the log level is debug or higher.
 func (l *Logger) Debugf(format string, args ...any) {
-	if l.threshold > LevelDebug {
+	if l.discards(LevelDebug) {
 		return
 	}
 
-	l.logf("debug", format, args...)
+	l.logf(context.Background(), LevelDebug, format, args...)
 }
 
 // Infof formats and prints a message if the log level is info or higher.
 func (l *Logger) Infof(format string, args ...any) {
-	if l.threshold > LevelInfo {
+	if l.discards(LevelInfo) {
+		return
+	}
+
+	l.logf(context.Background(), LevelInfo, format, args...)
+}
+
+// Warnf formats and prints a message if the log level is warn or higher.
+func (l *Logger) Warnf(format string, args ...any) {
+	if l.discards(LevelWarn) {
 		return
 	}
 
-	l.logf("info", format, args...)
+	l.logf(context.Background(), LevelWarn, format, args...)
 }
 
 // Errorf formats and prints a message if the log message is error or higher.
 func (l *Logger) Errorf(format string, args ...any) {
-	if l.threshold > LevelError {
+	if l.discards(LevelError) {
 		return
 	}
 
-	l.logf("error", format, args...)
+	l.logf(context.Background(), LevelError, format, args...)
+}
+
+// Fatalf formats and prints a message, whatever the log level, flushes the output
+// and exits the process with status 1. The exit can be overridden with WithExitFunc.
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.logf(context.Background(), LevelFatal, format, args...)
+	_ = l.Flush()
+	l.exit(1)
 }
 
-// logf prints the message to the output in JSON format.
-func (l *Logger) logf(level, format string, args ...any) {
-	entry := LogEntry{
-		Level:   strings.ToLower(level),
-		Message: fmt.Sprintf(format, args...),
-		//Time:    time.Now().Format(time.RFC3339),
+// Flush writes what the sampling suppressed so far, and any buffered entry
+// to the outputs, if they hold any, such as an AsyncWriter does.
+func (l *Logger) Flush() error {
+	l.flushSummaries()
+
+	var errs []error
+	for _, s := range l.sinks {
+		errs = append(errs, s.flush())
+	}
+	if l.fallback != nil {
+		errs = append(errs, flushOutput(l.fallback.output))
+	}
+	return errors.Join(errs...)
+}
+
+// logf encodes the entry, with the fields registered in the context,
+// and prints it to the outputs. Entries below the threshold are handed over
+// to the flight recorder instead.
+func (l *Logger) logf(ctx context.Context, lvl Level, format string, args ...any) {
+	now := l.clock()
+	recorded := !l.Enabled(lvl)
+	if recorded {
+		l.counts.suppress(lvl)
+		// the threshold may have been raised since the caller checked it
+		if l.flight == nil {
+			return
+		}
+	} else if !l.sample(lvl, format, now) {
+		l.counts.suppress(lvl)
+		return
+	}
+
+	entry := Entry{
+		Time:       now,
+		Level:      lvl,
+		Message:    fmt.Sprintf(format, resolveArgs(args)...),
+		Logger:     l.name,
+		Fields:     l.withContextFields(ctx),
+		timeFormat: l.timeFormat,
+	}
+
+	if l.caller {
+		entry.setCaller(callerPC(l.callerSkip))
+	}
+
+	if l.errorStack {
+		// skip logf and the logging method
+		entry.Fields = withErrorStacks(entry.Fields, 2+l.callerSkip)
 	}
 
-	b, err := json.Marshal(entry)
-	if err != nil {
-		// fallback if JSON fails
-		fmt.Fprintf(l.output, "[%-6s] %s\n", level, entry.Message)
+	if recorded {
+		l.flight.record(entry)
 		return
 	}
 
-	fmt.Fprintln(l.output, string(b))
+	l.write(entry)
+}
+
+// write counts and writes an entry logged by the caller, preceded by the ones
+// kept by the flight recorder if it is an error. Entries the logger makes up,
+// such as the summaries of the sampling, go to writeEntry instead.
+func (l *Logger) write(entry Entry) {
+	l.counts.emit(entry.Level)
+
+	if entry.Level >= LevelError {
+		l.writeBackfill()
+	}
+
+	l.writeEntry(entry)
+}
+
+// writeEntry hands the entry over to the slog handler if there is one,
+// or to every sink. A failing sink doesn't prevent the others from being written to,
+// and its error is reported to the error handler.
+func (l *Logger) writeEntry(entry Entry) {
+	entry.Fields, _ = resolveFields(entry.Fields)
+	l.redactor.redact(&entry)
+
+	if l.handler != nil {
+		forwardToSlog(l.handler, entry)
+		return
+	}
+
+	for _, s := range l.sinks {
+		if err := s.write(entry, l.fallback); err != nil && l.errorHandler != nil {
+			l.errorHandler(err)
+		}
+	}
 }
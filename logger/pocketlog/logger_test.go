@@ -2,9 +2,13 @@ package pocketlog_test
 
 import (
 	"encoding/json"
+	"io"
 	"learn-go-pockets/logger/pocketlog"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 const (
@@ -13,11 +17,33 @@ const (
 	errorMessage = "To err is human, to forgive is divine."
 )
 
+// fixedTime is the time returned by fixedClock.
+var fixedTime = time.Date(2025, time.March, 14, 15, 9, 26, 535897932, time.UTC)
+
+// fixedTimeText is fixedTime in the default time format.
+const fixedTimeText = "2025-03-14T15:09:26.535897932Z"
+
+// raceEnabled tells whether the race detector is on, see race_test.go.
+var raceEnabled bool
+
+// fixedClock always returns fixedTime, to keep outputs reproducible.
+func fixedClock() time.Time {
+	return fixedTime
+}
+
 func ExampleLogger_Debugf() {
-	debugLogger := pocketlog.New(pocketlog.LevelDebug)
+	debugLogger := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithClock(fixedClock))
 	debugLogger.Debugf("Hello, %s!", "world")
 	// Output:
-	// {"level":"debug","message":"Hello, world!"}
+	// {"time":"2025-03-14T15:09:26.535897932Z","level":"debug","message":"Hello, world!"}
+}
+
+func ExampleLogger_With() {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithClock(fixedClock))
+	reqLogger := lgr.With(pocketlog.Field{Key: "request_id", Value: "f3a9"})
+	reqLogger.Infof("Shelf %s loaded", "sci-fi")
+	// Output:
+	// {"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"Shelf sci-fi loaded","request_id":"f3a9"}
 }
 
 func TestLogger_DebugfInfofErrorf(t *testing.T) {
@@ -28,22 +54,22 @@ func TestLogger_DebugfInfofErrorf(t *testing.T) {
 		"debug": {
 			level: pocketlog.LevelDebug,
 			expected: []pocketlog.LogEntry{
-				{"debug", debugMessage},
-				{"info", infoMessage},
-				{"error", errorMessage},
+				{Time: fixedTimeText, Level: "debug", Message: debugMessage},
+				{Time: fixedTimeText, Level: "info", Message: infoMessage},
+				{Time: fixedTimeText, Level: "error", Message: errorMessage},
 			},
 		},
 		"info": {
 			level: pocketlog.LevelInfo,
 			expected: []pocketlog.LogEntry{
-				{"info", infoMessage},
-				{"error", errorMessage},
+				{Time: fixedTimeText, Level: "info", Message: infoMessage},
+				{Time: fixedTimeText, Level: "error", Message: errorMessage},
 			},
 		},
 		"error": {
 			level: pocketlog.LevelError,
 			expected: []pocketlog.LogEntry{
-				{"error", errorMessage},
+				{Time: fixedTimeText, Level: "error", Message: errorMessage},
 			},
 		},
 	}
@@ -52,7 +78,7 @@ func TestLogger_DebugfInfofErrorf(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			tw := &testWriter{}
 
-			testedLogger := pocketlog.New(tc.level, pocketlog.WithOutput(tw))
+			testedLogger := pocketlog.New(tc.level, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
 
 			testedLogger.Debugf(debugMessage)
 			testedLogger.Infof(infoMessage)
@@ -78,6 +104,441 @@ func TestLogger_DebugfInfofErrorf(t *testing.T) {
 	}
 }
 
+func TestLogger_TracefWarnf(t *testing.T) {
+	tests := map[string]struct {
+		level    pocketlog.Level
+		expected []string
+	}{
+		"trace": {level: pocketlog.LevelTrace, expected: []string{"trace", "warn"}},
+		"warn":  {level: pocketlog.LevelWarn, expected: []string{"warn"}},
+		"error": {level: pocketlog.LevelError},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			testedLogger := pocketlog.New(tc.level, pocketlog.WithOutput(tw))
+
+			testedLogger.Tracef(debugMessage)
+			testedLogger.Warnf(infoMessage)
+
+			lines := splitLines(tw.contents)
+			if len(lines) != len(tc.expected) {
+				t.Fatalf("expected %d log lines, got %d", len(tc.expected), len(lines))
+			}
+
+			for i, line := range lines {
+				var got pocketlog.LogEntry
+				if err := json.Unmarshal([]byte(line), &got); err != nil {
+					t.Fatalf("invalid JSON log: %v", err)
+				}
+
+				if got.Level != tc.expected[i] {
+					t.Errorf("expected level %s, got %s", tc.expected[i], got.Level)
+				}
+			}
+		})
+	}
+}
+
+func TestLogger_Fatalf(t *testing.T) {
+	tw := &testWriter{}
+	aw := pocketlog.NewAsyncWriter(tw, 16, pocketlog.OverflowBlock)
+	defer aw.Close()
+
+	exitCode := -1
+	testedLogger := pocketlog.New(pocketlog.LevelFatal,
+		pocketlog.WithOutput(aw),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithExitFunc(func(code int) { exitCode = code }),
+	)
+
+	testedLogger.Errorf(errorMessage)
+	testedLogger.Fatalf("Shelf %s is on fire", "sci-fi")
+
+	if exitCode != 1 {
+		t.Errorf("expected exit code 1, got %d", exitCode)
+	}
+
+	// the async writer must have been flushed before exiting
+	expected := `{"time":"` + fixedTimeText + `","level":"fatal","message":"Shelf sci-fi is on fire"}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %s, got %s", expected, tw.contents)
+	}
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	tw := &testWriter{}
+
+	parent := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+	child := parent.With(pocketlog.Field{Key: "user", Value: "ada"})
+
+	child.Debugf("hidden")
+	parent.SetLevel(pocketlog.LevelDebug)
+	child.Debugf("shown")
+
+	if child.Level() != pocketlog.LevelDebug {
+		t.Errorf("expected the child to share the level, got %v", child.Level())
+	}
+
+	lines := splitLines(tw.contents)
+	if len(lines) != 1 || !strings.Contains(lines[0], `"message":"shown"`) {
+		t.Errorf("expected only the shown entry, got %v", lines)
+	}
+}
+
+func TestLogger_SetLevelConcurrently(t *testing.T) {
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(&testWriter{}))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			testedLogger.SetLevel(pocketlog.Level(i % 3))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			testedLogger.Infof(infoMessage)
+		}
+	}()
+	wg.Wait()
+}
+
+func TestLogger_LevelRaisedWhileLogging(t *testing.T) {
+	tw := &testWriter{}
+
+	// the clock is read between the checks of the threshold:
+	// raise it there, as another goroutine could
+	var testedLogger *pocketlog.Logger
+	testedLogger = pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(func() time.Time {
+			testedLogger.SetLevel(pocketlog.LevelError)
+			return fixedTime
+		}),
+	)
+
+	testedLogger.Infof(infoMessage)
+
+	if tw.contents != "" {
+		t.Errorf("expected the entry to be dropped, got %s", tw.contents)
+	}
+
+	expected := pocketlog.LevelStats{Suppressed: 1}
+	if got := testedLogger.LevelStats(pocketlog.LevelInfo); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestLogger_With(t *testing.T) {
+	tests := map[string]struct {
+		fields   [][]pocketlog.Field
+		expected string
+		// prefix is set when the end of the line can't be known in advance
+		prefix bool
+	}{
+		"no fields": {
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"hello"}`,
+		},
+		"typed values": {
+			fields: [][]pocketlog.Field{{
+				{Key: "user", Value: "ada"},
+				{Key: "books", Value: 3},
+				{Key: "admin", Value: false},
+			}},
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"hello","user":"ada","books":3,"admin":false}`,
+		},
+		"order of first appearance": {
+			fields: [][]pocketlog.Field{
+				{{Key: "b", Value: 1}, {Key: "a", Value: 2}},
+				{{Key: "c", Value: 3}},
+			},
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"hello","b":1,"a":2,"c":3}`,
+		},
+		"child overrides parent value": {
+			fields: [][]pocketlog.Field{
+				{{Key: "shelf", Value: "fantasy"}, {Key: "user", Value: "ada"}},
+				{{Key: "shelf", Value: "sci-fi"}},
+			},
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"hello","shelf":"sci-fi","user":"ada"}`,
+		},
+		"reserved keys are prefixed": {
+			fields: [][]pocketlog.Field{{
+				{Key: "level", Value: "custom"},
+				{Key: "message", Value: "shadow"},
+			}},
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"hello","fields.level":"custom","fields.message":"shadow"}`,
+		},
+		"unmarshallable value is printed": {
+			fields: [][]pocketlog.Field{{
+				{Key: "ch", Value: make(chan int)},
+			}},
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"hello","ch":"0x`,
+			// the address of the channel varies
+			prefix: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
+			for _, fields := range tc.fields {
+				testedLogger = testedLogger.With(fields...)
+			}
+
+			testedLogger.Infof("hello")
+
+			got := strings.TrimSuffix(tw.contents, "\n")
+			if tc.prefix {
+				if !strings.HasPrefix(got, tc.expected) || !strings.HasSuffix(got, `"}`) {
+					t.Errorf("expected %s...\"}, got %s", tc.expected, got)
+				}
+				return
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLogger_WithDoesNotAlterParent(t *testing.T) {
+	tw := &testWriter{}
+
+	parent := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
+	child := parent.With(pocketlog.Field{Key: "user", Value: "ada"})
+	_ = child.With(pocketlog.Field{Key: "shelf", Value: "sci-fi"})
+
+	parent.Infof("parent")
+	child.Infof("child")
+
+	expected := `{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"parent"}` + "\n" +
+		`{"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"child","user":"ada"}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %q, got %q", expected, tw.contents)
+	}
+}
+
+func TestLogger_TimeFormat(t *testing.T) {
+	tests := map[string]struct {
+		format   []pocketlog.Option
+		expected string
+	}{
+		"default": {
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z",`,
+		},
+		"RFC3339Nano": {
+			format:   []pocketlog.Option{pocketlog.WithTimeFormat(pocketlog.TimeFormatRFC3339Nano)},
+			expected: `{"time":"2025-03-14T15:09:26.535897932Z",`,
+		},
+		"unix millis": {
+			format:   []pocketlog.Option{pocketlog.WithTimeFormat(pocketlog.TimeFormatUnixMilli)},
+			expected: `{"time":1741964966535,`,
+		},
+		"custom layout": {
+			format:   []pocketlog.Option{pocketlog.WithTimeFormat(time.DateTime)},
+			expected: `{"time":"2025-03-14 15:09:26",`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			opts := append([]pocketlog.Option{pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock)}, tc.format...)
+			testedLogger := pocketlog.New(pocketlog.LevelInfo, opts...)
+
+			testedLogger.Infof(infoMessage)
+
+			if !strings.HasPrefix(tw.contents, tc.expected) {
+				t.Errorf("expected %s to start with %s", tw.contents, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLogger_DefaultClock(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+	before := time.Now()
+	testedLogger.Infof(infoMessage)
+	after := time.Now()
+
+	var got pocketlog.LogEntry
+	if err := json.Unmarshal([]byte(tw.contents), &got); err != nil {
+		t.Fatalf("invalid JSON log: %v", err)
+	}
+
+	logged, err := time.Parse(time.RFC3339Nano, got.Time)
+	if err != nil {
+		t.Fatalf("invalid time %q: %v", got.Time, err)
+	}
+
+	if logged.Before(before) || logged.After(after) {
+		t.Errorf("expected a time between %v and %v, got %v", before, after, logged)
+	}
+}
+
+func TestLogger_ConcurrentWrites(t *testing.T) {
+	const goroutines, entries = 8, 100
+
+	tw := &byteByByteWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child := testedLogger.With(pocketlog.Field{Key: "goroutine", Value: g})
+			for i := range entries {
+				child.Infof("entry %d", i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := splitLines(tw.contents.String())
+	if len(lines) != goroutines*entries {
+		t.Fatalf("expected %d log lines, got %d", goroutines*entries, len(lines))
+	}
+
+	for _, line := range lines {
+		var got pocketlog.LogEntry
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("interleaved log line %q: %v", line, err)
+		}
+	}
+}
+
+func TestLogger_Enabled(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(io.Discard))
+
+	for lvl, expected := range map[pocketlog.Level]bool{
+		pocketlog.LevelDebug: false,
+		pocketlog.LevelInfo:  true,
+		pocketlog.LevelError: true,
+	} {
+		if got := lgr.Enabled(lvl); got != expected {
+			t.Errorf("expected Enabled(%s) to be %t, got %t", lvl, expected, got)
+		}
+	}
+
+	lgr.SetLevel(pocketlog.LevelDebug)
+	if !lgr.Enabled(pocketlog.LevelDebug) {
+		t.Errorf("expected debug to be enabled after SetLevel")
+	}
+}
+
+func TestLogger_Lazy(t *testing.T) {
+	calls := 0
+	stats := pocketlog.Lazy(func() any {
+		calls++
+		return map[string]int{"books": calls}
+	})
+
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
+	child := lgr.With(pocketlog.Field{Key: "stats", Value: stats})
+
+	child.Debugf("suppressed %v", stats)
+	if calls != 0 {
+		t.Fatalf("expected no call for a suppressed entry, got %d", calls)
+	}
+
+	child.Infof("first")
+	child.Infof("computed %v", stats)
+
+	expected := `{"time":"` + fixedTimeText + `","level":"info","message":"first","stats":{"books":1}}` + "\n" +
+		`{"time":"` + fixedTimeText + `","level":"info","message":"computed map[books:2]","stats":{"books":3}}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %s, got %s", expected, tw.contents)
+	}
+}
+
+func TestLogger_Allocations(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(io.Discard))
+	child := lgr.With(pocketlog.Field{Key: "shelf", Value: "sci-fi"})
+
+	tests := map[string]struct {
+		log      func()
+		expected float64
+	}{
+		"suppressed": {
+			log:      func() { child.Debugf("%d books on %s", 42, "sci-fi") },
+			expected: 0,
+		},
+		// the formatted message is the only allocation
+		"emitted": {
+			log:      func() { child.Infof(infoMessage) },
+			expected: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if raceEnabled && tc.expected > 0 {
+				t.Skip("the race detector makes sync.Pool drop buffers")
+			}
+
+			if got := testing.AllocsPerRun(100, tc.log); got > tc.expected {
+				t.Errorf("expected at most %v allocations, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func BenchmarkLogger(b *testing.B) {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(io.Discard))
+	child := lgr.With(pocketlog.Field{Key: "shelf", Value: "sci-fi"})
+
+	b.Run("suppressed", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			child.Debugf("%d books on %s", 42, "sci-fi")
+		}
+	})
+
+	b.Run("emitted", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			child.Infof(infoMessage)
+		}
+	})
+
+	b.Run("emitted with arguments", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			child.Infof("%d books on %s", 42, "sci-fi")
+		}
+	})
+}
+
+// byteByByteWriter is an io.Writer that writes one byte at a time, giving
+// other goroutines the opportunity to interleave their own writes.
+// It is not safe for concurrent use.
+type byteByByteWriter struct {
+	contents strings.Builder
+}
+
+// Write implements the io.Writer interface.
+func (w *byteByByteWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		w.contents.WriteByte(b)
+		runtime.Gosched()
+	}
+	return len(p), nil
+}
+
 // testWriter is a struct that implements io.Writer.
 // We use it to validate that we can write to a specific output.
 type testWriter struct {
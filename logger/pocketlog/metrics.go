@@ -0,0 +1,118 @@
+package pocketlog
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// levels lists the levels, from the lowest to the highest.
+var levels = []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+
+// levelCounts counts the entries of every level. It is shared with child loggers.
+type levelCounts struct {
+	emitted    [LevelFatal + 1]atomic.Uint64
+	suppressed [LevelFatal + 1]atomic.Uint64
+}
+
+// emit counts an entry handed over to the outputs.
+func (c *levelCounts) emit(lvl Level) {
+	if lvl <= LevelFatal {
+		c.emitted[lvl].Add(1)
+	}
+}
+
+// suppress counts an entry that was not handed over to the outputs.
+func (c *levelCounts) suppress(lvl Level) {
+	if lvl <= LevelFatal {
+		c.suppressed[lvl].Add(1)
+	}
+}
+
+// LevelStats counts the entries logged at a level.
+type LevelStats struct {
+	// Emitted counts the entries handed over to the outputs.
+	Emitted uint64 `json:"emitted"`
+	// Suppressed counts the entries below the threshold,
+	// or left out by sampling.
+	Suppressed uint64 `json:"suppressed"`
+}
+
+// LevelStats returns the counts of the entries logged at the given level since the
+// logger was created, by the logger and the ones sharing its threshold.
+// Entries logged through an slog.Logger are only counted once emitted.
+func (l *Logger) LevelStats(lvl Level) LevelStats {
+	if lvl > LevelFatal {
+		return LevelStats{}
+	}
+
+	return LevelStats{
+		Emitted:    l.counts.emitted[lvl].Load(),
+		Suppressed: l.counts.suppressed[lvl].Load(),
+	}
+}
+
+// Expvar returns an expvar.Var reporting the LevelStats of every level,
+// and the WriteStats of the logger, to be published under a name of your choice:
+//
+//	expvar.Publish("pocketlog", lgr.Expvar())
+func (l *Logger) Expvar() expvar.Var {
+	return expvar.Func(func() any {
+		entries := make(map[string]LevelStats, len(levels))
+		for _, lvl := range levels {
+			entries[lvl.String()] = l.LevelStats(lvl)
+		}
+
+		stats := l.WriteStats()
+		return map[string]any{
+			"entries": entries,
+			"writes": map[string]uint64{
+				"written": stats.Written,
+				"failed":  stats.Failed,
+				"dropped": stats.Dropped,
+			},
+		}
+	})
+}
+
+// MetricsHandler returns an http.Handler serving the LevelStats of every level,
+// and the WriteStats of the logger, in the text exposition format of Prometheus.
+func (l *Logger) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var buf bytes.Buffer
+
+		writeMetricHeader(&buf, "pocketlog_entries_emitted_total", "Entries handed over to the outputs, by level.")
+		for _, lvl := range levels {
+			fmt.Fprintf(&buf, "pocketlog_entries_emitted_total{level=%q} %d\n", lvl, l.LevelStats(lvl).Emitted)
+		}
+
+		writeMetricHeader(&buf, "pocketlog_entries_suppressed_total", "Entries below the threshold, or left out by sampling, by level.")
+		for _, lvl := range levels {
+			fmt.Fprintf(&buf, "pocketlog_entries_suppressed_total{level=%q} %d\n", lvl, l.LevelStats(lvl).Suppressed)
+		}
+
+		stats := l.WriteStats()
+		writeMetricHeader(&buf, "pocketlog_writes_total", "Entries written to an output, or to the fallback.")
+		fmt.Fprintf(&buf, "pocketlog_writes_total %d\n", stats.Written)
+		writeMetricHeader(&buf, "pocketlog_write_failures_total", "Writes to an output that returned an error.")
+		fmt.Fprintf(&buf, "pocketlog_write_failures_total %d\n", stats.Failed)
+		writeMetricHeader(&buf, "pocketlog_dropped_entries_total", "Entries lost, as no output could write them.")
+		fmt.Fprintf(&buf, "pocketlog_dropped_entries_total %d\n", stats.Dropped)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// writeMetricHeader writes the help and type of a counter.
+func writeMetricHeader(buf *bytes.Buffer, name, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+}
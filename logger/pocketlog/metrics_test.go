@@ -0,0 +1,140 @@
+package pocketlog_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"learn-go-pockets/logger/pocketlog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCountedLogger returns a logger that logged 2 info, 1 error,
+// and 3 debug entries, below its threshold.
+func newCountedLogger() *pocketlog.Logger {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(io.Discard))
+	child := lgr.Named("loader")
+
+	lgr.Debugf(debugMessage)
+	child.Debugf(debugMessage)
+	lgr.DebugfCtx(context.Background(), debugMessage)
+	lgr.Infof(infoMessage)
+	child.Infof(infoMessage)
+	child.Errorf(errorMessage)
+
+	return lgr
+}
+
+func TestLogger_LevelStats(t *testing.T) {
+	lgr := newCountedLogger()
+
+	for lvl, expected := range map[pocketlog.Level]pocketlog.LevelStats{
+		pocketlog.LevelTrace: {},
+		pocketlog.LevelDebug: {Suppressed: 3},
+		pocketlog.LevelInfo:  {Emitted: 2},
+		pocketlog.LevelError: {Emitted: 1},
+	} {
+		if got := lgr.LevelStats(lvl); got != expected {
+			t.Errorf("expected %+v at %s, got %+v", expected, lvl, got)
+		}
+	}
+}
+
+func TestLogger_LevelStatsWithSampling(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(io.Discard),
+		pocketlog.WithSampling(time.Hour, 1, 0),
+	)
+
+	for range 3 {
+		lgr.Infof(infoMessage)
+	}
+
+	expected := pocketlog.LevelStats{Emitted: 1, Suppressed: 2}
+	if got := lgr.LevelStats(pocketlog.LevelInfo); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestLogger_LevelStatsWithSamplingSummaries(t *testing.T) {
+	tw := &testWriter{}
+
+	now := fixedTime
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(func() time.Time { return now }),
+		pocketlog.WithSampling(time.Second, 1, 0),
+	)
+
+	lgr.Errorf(errorMessage)
+	lgr.Errorf(errorMessage)
+	now = now.Add(time.Second)
+	// the summary of the previous interval comes first
+	lgr.Errorf(errorMessage)
+
+	if lines := splitLines(tw.contents); len(lines) != 3 {
+		t.Fatalf("expected 2 entries and a summary, got %d lines", len(lines))
+	}
+
+	expected := pocketlog.LevelStats{Emitted: 2, Suppressed: 1}
+	if got := lgr.LevelStats(pocketlog.LevelError); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestLogger_Expvar(t *testing.T) {
+	lgr := newCountedLogger()
+
+	var got struct {
+		Entries map[string]pocketlog.LevelStats `json:"entries"`
+		Writes  map[string]uint64               `json:"writes"`
+	}
+	if err := json.Unmarshal([]byte(lgr.Expvar().String()), &got); err != nil {
+		t.Fatalf("invalid expvar JSON: %v", err)
+	}
+
+	if got.Entries["debug"].Suppressed != 3 || got.Entries["info"].Emitted != 2 || len(got.Entries) != 6 {
+		t.Errorf("unexpected entries %+v", got.Entries)
+	}
+	if got.Writes["written"] != 3 {
+		t.Errorf("expected 3 written entries, got %+v", got.Writes)
+	}
+}
+
+func TestLogger_MetricsHandler(t *testing.T) {
+	lgr := newCountedLogger()
+
+	rec := httptest.NewRecorder()
+	lgr.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	for _, expected := range []string{
+		"# TYPE pocketlog_entries_emitted_total counter\n",
+		`pocketlog_entries_emitted_total{level="info"} 2` + "\n",
+		`pocketlog_entries_emitted_total{level="error"} 1` + "\n",
+		`pocketlog_entries_suppressed_total{level="debug"} 3` + "\n",
+		`pocketlog_entries_suppressed_total{level="fatal"} 0` + "\n",
+		"pocketlog_writes_total 3\n",
+		"pocketlog_write_failures_total 0\n",
+		"pocketlog_dropped_entries_total 0\n",
+	} {
+		if !strings.Contains(rec.Body.String(), expected) {
+			t.Errorf("expected %q in the metrics, got:\n%s", expected, rec.Body.String())
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	lgr.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("expected status 405 with an Allow header, got %d %q", rec.Code, rec.Header().Get("Allow"))
+	}
+}
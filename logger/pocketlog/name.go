@@ -0,0 +1,155 @@
+package pocketlog
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Named returns a child logger whose name, appended to the name of its parent
+// with a dot, is written in every entry under the "logger" key:
+//
+//	loader := lgr.Named("bookworms").Named("loader") // bookworms.loader
+//
+// The threshold of a named logger can be overridden with a LevelSpec.
+// The child shares the outputs and threshold of its parent, as With does.
+func (l *Logger) Named(name string) *Logger {
+	child := *l
+	if l.name != "" && name != "" {
+		child.name = l.name + "." + name
+	} else {
+		child.name = l.name + name
+	}
+	return &child
+}
+
+// Name returns the name of the logger, empty unless it was derived with Named.
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// LevelSpec holds a threshold, and thresholds overriding it for named loggers.
+// It is written as a comma-separated list of a level, and of name=level pairs:
+//
+//	info,bookworms=debug,bookworms.cache=error
+//
+// An override applies to the loggers with that name, and to the ones
+// derived from them: with the spec above, bookworms.loader logs debug entries,
+// bookworms.cache only errors, and other loggers start at info.
+//
+// LevelSpec implements encoding.TextUnmarshaler, so that it can be read
+// from a flag with flag.TextVar.
+type LevelSpec struct {
+	threshold    Level
+	hasThreshold bool
+	overrides    map[string]Level
+}
+
+// ParseLevelSpec reads a spec such as "info,bookworms=debug".
+// Names may end with ".*", which is ignored: "bookworms.*=debug" is "bookworms=debug".
+// The threshold may be left out, in which case applying the spec leaves it untouched.
+func ParseLevelSpec(spec string) (LevelSpec, error) {
+	parsed := LevelSpec{overrides: make(map[string]Level)}
+
+	for item := range strings.SplitSeq(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, levelName, found := strings.Cut(item, "=")
+		if !found {
+			lvl, err := ParseLevel(item)
+			if err != nil {
+				return LevelSpec{}, fmt.Errorf("invalid level spec %q: %w", spec, err)
+			}
+			parsed.threshold, parsed.hasThreshold = lvl, true
+			continue
+		}
+
+		name = strings.TrimSuffix(strings.TrimSpace(name), ".*")
+		if name == "" {
+			return LevelSpec{}, fmt.Errorf("invalid level spec %q: missing logger name in %q", spec, item)
+		}
+
+		lvl, err := ParseLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return LevelSpec{}, fmt.Errorf("invalid level spec %q: %w", spec, err)
+		}
+		parsed.overrides[name] = lvl
+	}
+
+	return parsed, nil
+}
+
+// String returns the spec in the form ParseLevelSpec reads,
+// with the overrides sorted by name.
+func (s LevelSpec) String() string {
+	var items []string
+	if s.hasThreshold {
+		items = append(items, s.threshold.String())
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.overrides)) {
+		items = append(items, name+"="+s.overrides[name].String())
+	}
+
+	return strings.Join(items, ",")
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s LevelSpec) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *LevelSpec) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevelSpec(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = parsed
+	return nil
+}
+
+// SetLevelSpec applies the spec while the logger is in use: it sets the
+// threshold, if the spec has one, and replaces the overrides of named loggers.
+// As with SetLevel, this affects every logger sharing the threshold.
+func (l *Logger) SetLevelSpec(spec LevelSpec) {
+	if spec.hasThreshold {
+		l.threshold.Store(uint32(spec.threshold))
+	}
+
+	overrides := maps.Clone(spec.overrides)
+	l.overrides.byName.Store(&overrides)
+}
+
+// levelOverrides holds the thresholds of named loggers, by name.
+// It is shared with child loggers.
+type levelOverrides struct {
+	byName atomic.Pointer[map[string]Level]
+}
+
+// lookup returns the threshold overriding the one of the logger with the given name:
+// the one set for the name itself, or for its closest dotted ancestor.
+func (o *levelOverrides) lookup(name string) (Level, bool) {
+	byName := o.byName.Load()
+	if name == "" || byName == nil || len(*byName) == 0 {
+		return 0, false
+	}
+
+	for {
+		if lvl, ok := (*byName)[name]; ok {
+			return lvl, true
+		}
+
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return 0, false
+		}
+		name = name[:i]
+	}
+}
@@ -0,0 +1,138 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"flag"
+	"learn-go-pockets/logger/pocketlog"
+	"os"
+	"testing"
+)
+
+func ExampleLogger_Named() {
+	spec, err := pocketlog.ParseLevelSpec("error,bookworms=debug")
+	if err != nil {
+		panic(err)
+	}
+
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(os.Stdout),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithLevelSpec(spec),
+	)
+
+	lgr.Infof("Starting")
+	lgr.Named("bookworms").Named("loader").Debugf("Loading shelves")
+	// Output:
+	// time=2025-03-14T15:09:26.535897932Z level=debug message="Loading shelves" logger=bookworms.loader
+}
+
+func TestLogger_Named(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
+
+	loader := lgr.Named("bookworms").Named("loader")
+	if loader.Name() != "bookworms.loader" {
+		t.Errorf("expected name bookworms.loader, got %q", loader.Name())
+	}
+	if lgr.Name() != "" {
+		t.Errorf("expected the parent to stay unnamed, got %q", lgr.Name())
+	}
+
+	loader.With(pocketlog.Field{Key: "logger", Value: "field"}).Infof(infoMessage)
+
+	expected := `{"time":"` + fixedTimeText + `","level":"info","message":"` + infoMessage +
+		`","logger":"bookworms.loader","fields.logger":"field"}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %s, got %s", expected, tw.contents)
+	}
+}
+
+func TestLogger_SetLevelSpec(t *testing.T) {
+	lgr := pocketlog.New(pocketlog.LevelInfo)
+	bookworms := lgr.Named("bookworms")
+	loader := bookworms.Named("loader")
+	cache := bookworms.Named("cache")
+	other := lgr.Named("bookwormsdb")
+
+	spec, err := pocketlog.ParseLevelSpec("error, bookworms.*=debug, bookworms.cache=warn")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lgr.SetLevelSpec(spec)
+
+	for named, expected := range map[*pocketlog.Logger]pocketlog.Level{
+		lgr:       pocketlog.LevelError,
+		bookworms: pocketlog.LevelDebug,
+		loader:    pocketlog.LevelDebug,
+		cache:     pocketlog.LevelWarn,
+		other:     pocketlog.LevelError,
+	} {
+		if got := named.Level(); got != expected {
+			t.Errorf("expected %q to be at %s, got %s", named.Name(), expected, got)
+		}
+	}
+
+	// SetLevel only changes the threshold, and the overrides are replaced as a whole
+	lgr.SetLevel(pocketlog.LevelTrace)
+	if other.Level() != pocketlog.LevelTrace || loader.Level() != pocketlog.LevelDebug {
+		t.Errorf("expected trace and debug, got %s and %s", other.Level(), loader.Level())
+	}
+
+	spec, err = pocketlog.ParseLevelSpec("cache=fatal")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loader.SetLevelSpec(spec)
+	if loader.Level() != pocketlog.LevelTrace || cache.Level() != pocketlog.LevelTrace {
+		t.Errorf("expected trace for both, got %s and %s", loader.Level(), cache.Level())
+	}
+}
+
+func TestParseLevelSpec(t *testing.T) {
+	tests := map[string]struct {
+		spec     string
+		expected string
+		err      error
+	}{
+		"threshold only": {spec: "INFO", expected: "info"},
+		"overrides only": {spec: "b=warn,a=debug", expected: "a=debug,b=warn"},
+		"both":           {spec: " info , bookworms.* = debug ,", expected: "info,bookworms=debug"},
+		"empty":          {spec: "", expected: ""},
+		"unknown level":  {spec: "info,bookworms=chatty", err: pocketlog.ErrUnknownLevel},
+		"missing name":   {spec: "=debug", err: errors.New("any")},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := pocketlog.ParseLevelSpec(tc.spec)
+
+			switch {
+			case tc.err == nil && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tc.err != nil && err == nil:
+				t.Fatalf("expected an error, got %q", spec)
+			case errors.Is(tc.err, pocketlog.ErrUnknownLevel) && !errors.Is(err, pocketlog.ErrUnknownLevel):
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+
+			if got := spec.String(); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLevelSpec_Flag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var spec pocketlog.LevelSpec
+	fs.TextVar(&spec, "log-levels", pocketlog.LevelSpec{}, "log levels")
+
+	if err := fs.Parse([]string{"-log-levels", "warn,bookworms=trace"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spec.String() != "warn,bookworms=trace" {
+		t.Errorf("expected warn,bookworms=trace, got %q", spec)
+	}
+}
@@ -1,6 +1,12 @@
 package pocketlog
 
-import "io"
+import (
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
 
 // Option defines a functional option to our logger.
 type Option func(*Logger)
@@ -8,6 +14,181 @@ type Option func(*Logger)
 // WithOutput returns a configuration function that sets the output of logs.
 func WithOutput(output io.Writer) Option {
 	return func(lgr *Logger) {
-		lgr.output = output
+		lgr.sinks[0].output = output
+		lgr.outputSet = true
+	}
+}
+
+// WithClock returns a configuration function that sets the clock
+// used to timestamp entries. It is mostly useful to get reproducible output.
+func WithClock(clock func() time.Time) Option {
+	return func(lgr *Logger) {
+		lgr.clock = clock
+	}
+}
+
+// WithTimeFormat returns a configuration function that sets how timestamps
+// are written: TimeFormatRFC3339Nano, TimeFormatUnixMilli, or any layout
+// accepted by time.Time.Format.
+func WithTimeFormat(format string) Option {
+	return func(lgr *Logger) {
+		lgr.timeFormat = format
+	}
+}
+
+// WithEncoder returns a configuration function that sets the format
+// in which entries are written, such as JSONEncoder, LogfmtEncoder or TextEncoder.
+func WithEncoder(enc Encoder) Option {
+	return func(lgr *Logger) {
+		lgr.sinks[0].encoder = enc
+		lgr.outputSet = true
+	}
+}
+
+// WithExitFunc returns a configuration function that replaces os.Exit
+// as the function called by Fatalf, to flush other resources before exiting,
+// or to test code calling Fatalf.
+func WithExitFunc(exit func(code int)) Option {
+	return func(lgr *Logger) {
+		lgr.exit = exit
+	}
+}
+
+// WithSlogHandler returns a configuration function that forwards entries
+// to a slog.Handler, instead of encoding them to the output.
+// The threshold of the logger still applies, before the handler's own.
+func WithSlogHandler(h slog.Handler) Option {
+	return func(lgr *Logger) {
+		lgr.handler = h
+	}
+}
+
+// WithCaller returns a configuration function that adds the file, line and
+// function of the code that logged to each entry. Wrappers around the logger
+// should set skip to the number of frames they add, so that their own
+// callers are reported; otherwise, skip is 0.
+func WithCaller(skip int) Option {
+	return func(lgr *Logger) {
+		lgr.caller = true
+		lgr.callerSkip = skip
+	}
+}
+
+// WithSampling returns a configuration function that limits repetitive messages,
+// told apart by their level and format string. Over each interval, the first
+// messages are written, then only one in every thereafter, or none if it is 0.
+// The first message logged after an interval is preceded by entries reporting
+// how many messages were suppressed during it. These entries are also written
+// an interval after a message was first suppressed, and on Flush, should no
+// message come. Fatal messages are never sampled.
+func WithSampling(interval time.Duration, first, thereafter uint64) Option {
+	return func(lgr *Logger) {
+		lgr.sampler = &sampler{
+			interval:   interval,
+			first:      first,
+			thereafter: thereafter,
+		}
+	}
+}
+
+// WithRedactedKeys returns a configuration function that hides the values
+// of the fields with the given keys, regardless of their case, such as
+// "password" or "token". Fields in groups, such as "user.password", are hidden too,
+// as well as the members of structs and maps with these keys.
+func WithRedactedKeys(keys ...string) Option {
+	return func(lgr *Logger) {
+		r := lgr.redactorToConfigure()
+		for _, key := range keys {
+			r.keys[strings.ToLower(key)] = true
+		}
+	}
+}
+
+// WithRedactedPatterns returns a configuration function that hides the parts
+// of messages and field values matching any of the patterns, such as email
+// addresses or API keys. Values other than strings, errors included, are
+// matched in their text form; structs and maps in their JSON form.
+func WithRedactedPatterns(patterns ...*regexp.Regexp) Option {
+	return func(lgr *Logger) {
+		r := lgr.redactorToConfigure()
+		r.patterns = append(r.patterns, patterns...)
+	}
+}
+
+// WithSink returns a configuration function that adds an output to the logger,
+// with its own encoder and threshold. Entries are only written to it if they
+// pass both the threshold of the logger and its own; e.g. with a logger at
+// LevelDebug, a sink at LevelError only gets errors. Sinks are written to in turn:
+// wrap a slow output in an AsyncWriter so that it doesn't hold the others up.
+//
+// Unless WithOutput or WithEncoder configure it, the default output, Stdout,
+// is left out once a sink is added: entries only go to the sinks.
+func WithSink(output io.Writer, threshold Level, enc Encoder) Option {
+	return func(lgr *Logger) {
+		lgr.sinks = append(lgr.sinks, &sink{output: output, encoder: enc, threshold: threshold})
+	}
+}
+
+// WithContextKey returns a configuration function that adds the value stored
+// under key in the context, if any, to the entries logged with a context,
+// as the field with the given name. The request, trace and span IDs set by
+// ContextWithRequestID, ContextWithTraceID and ContextWithSpanID are always added.
+func WithContextKey(field string, key any) Option {
+	return func(lgr *Logger) {
+		lgr.contextKeys = append(lgr.contextKeys, contextField{field: field, key: key})
+	}
+}
+
+// WithErrorStack returns a configuration function that records the stack of
+// the code that logged an entry in the errors recorded with Err.
+// The skip depth set by WithCaller applies.
+func WithErrorStack() Option {
+	return func(lgr *Logger) {
+		lgr.errorStack = true
+	}
+}
+
+// WithLevelSpec returns a configuration function that applies the spec,
+// read with ParseLevelSpec: its threshold, if it has one, replaces the one
+// given to New, and its overrides set the thresholds of named loggers.
+func WithLevelSpec(spec LevelSpec) Option {
+	return func(lgr *Logger) {
+		lgr.SetLevelSpec(spec)
+	}
+}
+
+// WithFlightRecorder returns a configuration function that keeps the last size
+// entries below the threshold in memory. Whenever an error, or a fatal entry,
+// is written, they are written first, from the oldest to the newest, with a
+// "backfill" field set to true, giving context to what went wrong.
+// Keeping entries has a cost: they are formatted even though they are below the threshold.
+// A size of 0 or less disables the flight recorder.
+func WithFlightRecorder(size int) Option {
+	return func(lgr *Logger) {
+		if size <= 0 {
+			lgr.flight = nil
+			return
+		}
+		lgr.flight = newFlightRecorder(size)
+	}
+}
+
+// WithErrorHandler returns a configuration function that calls handle
+// with the errors returned by the outputs, which are ignored by default.
+// It is called synchronously, by the code logging the entry, and must not log
+// through the same logger.
+func WithErrorHandler(handle func(err error)) Option {
+	return func(lgr *Logger) {
+		lgr.errorHandler = handle
+	}
+}
+
+// WithFallback returns a configuration function that writes the entries an
+// output fails to write to the fallback output instead, such as os.Stderr.
+// Once an output failed after times in a row, it is given up on, and its
+// entries are written to the fallback only.
+func WithFallback(output io.Writer, after int) Option {
+	return func(lgr *Logger) {
+		lgr.fallback = &fallback{output: output, after: max(after, 1)}
 	}
 }
@@ -0,0 +1,591 @@
+package pocketlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OTLPSeverityNumber returns the OpenTelemetry severity number matching lvl,
+// the lowest of its range: 1 for trace, up to 21 for fatal.
+func (lvl Level) OTLPSeverityNumber() int {
+	switch lvl {
+	case LevelTrace:
+		return 1
+	case LevelDebug:
+		return 5
+	case LevelInfo:
+		return 9
+	case LevelWarn:
+		return 13
+	case LevelError:
+		return 17
+	default:
+		return 21
+	}
+}
+
+// OTLPEncoder writes each entry as an OpenTelemetry log record, in the JSON
+// encoding of the OTLP protocol. The trace_id and span_id fields, as set from
+// a context, become the trace and span IDs of the record if they are valid
+// hexadecimal IDs; the other fields, the logger name and the caller become
+// its attributes. The record is not terminated by a newline: it is meant to
+// be batched by an OTLPWriter.
+type OTLPEncoder struct{}
+
+// Encode implements the Encoder interface.
+func (OTLPEncoder) Encode(buf *bytes.Buffer, e Entry) error {
+	unixNano := strconv.FormatInt(e.Time.UnixNano(), 10)
+
+	buf.WriteString(`{"timeUnixNano":"`)
+	buf.WriteString(unixNano)
+	buf.WriteString(`","observedTimeUnixNano":"`)
+	buf.WriteString(unixNano)
+	buf.WriteString(`","severityNumber":`)
+	buf.WriteString(strconv.Itoa(e.Level.OTLPSeverityNumber()))
+	buf.WriteString(`,"severityText":`)
+	appendJSONString(buf, strings.ToUpper(e.Level.String()))
+	buf.WriteString(`,"body":{"stringValue":`)
+	appendJSONString(buf, e.Message)
+	buf.WriteString(`},"attributes":[`)
+
+	var traceID, spanID string
+	first := true
+	appendAttribute := func(key string, value any) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		buf.WriteString(`{"key":`)
+		appendJSONString(buf, key)
+		buf.WriteString(`,"value":`)
+		appendOTLPValue(buf, value)
+		buf.WriteByte('}')
+	}
+
+	if e.Logger != "" {
+		appendAttribute("logger.name", e.Logger)
+	}
+
+	if e.Caller != "" {
+		file, line := splitCaller(e.Caller)
+		appendAttribute("code.filepath", file)
+		if n, err := strconv.Atoi(line); err == nil {
+			appendAttribute("code.lineno", n)
+		}
+		appendAttribute("code.function", e.Func)
+	}
+
+	for _, f := range e.Fields {
+		id, _ := f.Value.(string)
+		switch {
+		case f.Key == "trace_id" && validOTLPID(id, 16):
+			traceID = id
+		case f.Key == "span_id" && validOTLPID(id, 8):
+			spanID = id
+		default:
+			appendAttribute(f.Key, f.Value)
+		}
+	}
+
+	buf.WriteByte(']')
+
+	if traceID != "" {
+		buf.WriteString(`,"traceId":`)
+		appendJSONString(buf, strings.ToLower(traceID))
+	}
+	if spanID != "" {
+		buf.WriteString(`,"spanId":`)
+		appendJSONString(buf, strings.ToLower(spanID))
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
+
+// validOTLPID tells whether id is the hexadecimal form of an ID of size bytes,
+// which isn't all zeros.
+func validOTLPID(id string, size int) bool {
+	if len(id) != 2*size || strings.Trim(id, "0") == "" {
+		return false
+	}
+
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// appendOTLPValue writes v to buf as an OTLP AnyValue. As in the JSON encoding
+// of protocol buffers, 64-bit integers are written as strings, as well as
+// floats that aren't numbers.
+func appendOTLPValue(buf *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case bool:
+		buf.WriteString(`{"boolValue":`)
+		buf.WriteString(strconv.FormatBool(v))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		buf.WriteString(`{"intValue":"`)
+		fmt.Fprint(buf, v)
+		buf.WriteByte('"')
+	case float32:
+		appendOTLPDouble(buf, float64(v))
+	case float64:
+		appendOTLPDouble(buf, v)
+	case Fields:
+		buf.WriteString(`{"kvlistValue":{"values":[`)
+		for i, f := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`{"key":`)
+			appendJSONString(buf, f.Key)
+			buf.WriteString(`,"value":`)
+			appendOTLPValue(buf, f.Value)
+			buf.WriteByte('}')
+		}
+		buf.WriteString(`]}`)
+	default:
+		buf.WriteString(`{"stringValue":`)
+		appendJSONString(buf, valueText(v))
+	}
+	buf.WriteByte('}')
+}
+
+// appendOTLPDouble writes the opening of a double AnyValue holding f.
+func appendOTLPDouble(buf *bytes.Buffer, f float64) {
+	buf.WriteString(`{"doubleValue":`)
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(`"NaN"`)
+	case math.IsInf(f, 1):
+		buf.WriteString(`"Infinity"`)
+	case math.IsInf(f, -1):
+		buf.WriteString(`"-Infinity"`)
+	default:
+		buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), f, 'g', -1, 64))
+	}
+}
+
+// OTLPWriter is an io.WriteCloser sending the records written by an OTLPEncoder
+// to an OpenTelemetry collector, over OTLP/HTTP with JSON bodies. Records are
+// batched, and sent in the background once a batch is full, or once the flush
+// interval elapsed. A failed export is retried with an exponential backoff if
+// the error is temporary. It is safe for concurrent use.
+//
+// Flush sends the pending records, retrying for the flush timeout at most, and
+// reports the errors of the exports since the previous flush. Close must be
+// called to release the background goroutine.
+type OTLPWriter struct {
+	endpoint      string
+	client        *http.Client
+	headers       http.Header
+	serviceName   string
+	batchSize     int
+	maxQueue      int
+	flushInterval time.Duration
+	flushTimeout  time.Duration
+	maxRetries    int
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+
+	// mu protects the pending records, and what happened to them.
+	mu      sync.Mutex
+	pending [][]byte
+	closed  bool
+	dropped uint64
+	err     error
+
+	// exportMu serialises the exports, so that records are sent in order.
+	exportMu sync.Mutex
+	// flushing counts the calls to Flush and Close waiting to export.
+	flushing atomic.Int32
+	// yield wakes the background goroutine waiting to retry,
+	// so that it hands over its records to a flush.
+	yield chan struct{}
+
+	// full is signalled when a batch is ready to be sent.
+	full chan struct{}
+	done chan struct{}
+	// stopped is closed once the background goroutine returned.
+	stopped chan struct{}
+}
+
+// OTLPOption defines a functional option to an OTLPWriter.
+type OTLPOption func(*OTLPWriter)
+
+// OTLPBatchSize returns a configuration function that sends at most n records
+// per request, 512 by default.
+func OTLPBatchSize(n int) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.batchSize = n
+	}
+}
+
+// OTLPMaxQueue returns a configuration function that keeps at most n records
+// waiting to be sent, 4096 by default. Writes are dropped, and return
+// ErrQueueFull, while the queue is full.
+func OTLPMaxQueue(n int) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.maxQueue = n
+	}
+}
+
+// OTLPFlushInterval returns a configuration function that sends the pending
+// records at least every interval, 5 seconds by default. With a zero or
+// negative interval, records are only sent once a batch is full, or on Flush.
+func OTLPFlushInterval(interval time.Duration) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.flushInterval = interval
+	}
+}
+
+// OTLPFlushTimeout returns a configuration function that bounds the time
+// Flush and Close spend sending the pending records, 5 seconds by default.
+// Flush stops retrying a failed export once the timeout elapsed.
+func OTLPFlushTimeout(timeout time.Duration) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.flushTimeout = timeout
+	}
+}
+
+// OTLPRetry returns a configuration function that retries a failed export at
+// most maxRetries times, waiting minBackoff before the first retry, and twice
+// as long before each of the next ones, up to maxBackoff.
+// By default, an export is retried 5 times, from 1 second up to 30 seconds.
+func OTLPRetry(maxRetries int, minBackoff, maxBackoff time.Duration) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.maxRetries = maxRetries
+		w.minBackoff = minBackoff
+		w.maxBackoff = maxBackoff
+	}
+}
+
+// OTLPHeader returns a configuration function that adds a header to the requests,
+// such as an API key.
+func OTLPHeader(key, value string) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.headers.Add(key, value)
+	}
+}
+
+// OTLPServiceName returns a configuration function that sets the service.name
+// attribute of the resource the records are sent for.
+func OTLPServiceName(name string) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.serviceName = name
+	}
+}
+
+// OTLPHTTPClient returns a configuration function that sends the requests with
+// the given client, instead of http.DefaultClient.
+func OTLPHTTPClient(client *http.Client) OTLPOption {
+	return func(w *OTLPWriter) {
+		w.client = client
+	}
+}
+
+// NewOTLPWriter returns an OTLPWriter sending records to the endpoint,
+// the URL of the logs of a collector, such as http://localhost:4318/v1/logs.
+// Use it with an OTLPEncoder:
+//
+//	otlp := pocketlog.NewOTLPWriter("http://localhost:4318/v1/logs")
+//	defer otlp.Close()
+//	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithSink(otlp, pocketlog.LevelInfo, pocketlog.OTLPEncoder{}))
+func NewOTLPWriter(endpoint string, opts ...OTLPOption) *OTLPWriter {
+	w := &OTLPWriter{
+		endpoint:      endpoint,
+		client:        http.DefaultClient,
+		headers:       make(http.Header),
+		batchSize:     512,
+		maxQueue:      4096,
+		flushInterval: 5 * time.Second,
+		flushTimeout:  5 * time.Second,
+		maxRetries:    5,
+		minBackoff:    time.Second,
+		maxBackoff:    30 * time.Second,
+		full:          make(chan struct{}, 1),
+		yield:         make(chan struct{}, 1),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+
+	for _, configFunc := range opts {
+		configFunc(w)
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Write implements the io.Writer interface. Each write is a single record,
+// which is queued to be sent.
+func (w *OTLPWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	if len(w.pending) >= w.maxQueue {
+		w.dropped++
+		return 0, ErrQueueFull
+	}
+
+	w.pending = append(w.pending, bytes.Clone(p))
+
+	if len(w.pending) >= w.batchSize {
+		select {
+		case w.full <- struct{}{}:
+		default:
+			// the background goroutine was already told
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends the pending records, and returns the first error met
+// while exporting records since the previous flush. A failed export is
+// retried until the flush timeout elapsed.
+func (w *OTLPWriter) Flush() error {
+	ctx, cancel := w.flushContext()
+	defer cancel()
+
+	w.export(ctx, w.maxRetries, false)
+	return w.takeErr()
+}
+
+// Close sends the pending records, without retrying if this fails, and stops
+// the background goroutine. It returns the first error met while exporting
+// records since the last flush. Sending is bounded by the flush timeout.
+func (w *OTLPWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	<-w.stopped
+
+	ctx, cancel := w.flushContext()
+	defer cancel()
+
+	w.export(ctx, 0, false)
+	return w.takeErr()
+}
+
+// Dropped returns the number of records discarded because the queue was full.
+func (w *OTLPWriter) Dropped() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.dropped
+}
+
+// run exports the pending records whenever a batch is full or the
+// flush interval elapsed, until the writer is closed.
+func (w *OTLPWriter) run() {
+	defer close(w.stopped)
+
+	var tick <-chan time.Time
+	if w.flushInterval > 0 {
+		ticker := time.NewTicker(w.flushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-w.full:
+		case <-tick:
+		case <-w.done:
+			return
+		}
+
+		w.export(context.Background(), w.maxRetries, true)
+	}
+}
+
+// flushContext returns the context bounding a call to Flush or Close,
+// and tells the background goroutine to hand over its records.
+func (w *OTLPWriter) flushContext() (context.Context, context.CancelFunc) {
+	w.flushing.Add(1)
+	select {
+	case w.yield <- struct{}{}:
+	default:
+		// the background goroutine was already told
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.flushTimeout)
+	return ctx, func() {
+		cancel()
+		w.flushing.Add(-1)
+	}
+}
+
+// errYield tells that the background goroutine handed over its records,
+// instead of waiting to retry.
+var errYield = errors.New("pocketlog: export handed over")
+
+// export sends the pending records, in batches, retrying a failed batch
+// at most maxRetries times while ctx is not done. The errors are recorded,
+// to be reported by Flush.
+func (w *OTLPWriter) export(ctx context.Context, maxRetries int, background bool) {
+	w.exportMu.Lock()
+	defer w.exportMu.Unlock()
+
+	if !background {
+		// this export takes the records over, there's nobody left to wake up
+		select {
+		case <-w.yield:
+		default:
+		}
+	}
+
+	w.mu.Lock()
+	records := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	for len(records) > 0 {
+		n := min(len(records), max(w.batchSize, 1))
+		err := w.send(ctx, records[:n], maxRetries, background)
+		if errors.Is(err, errYield) {
+			// queue the records back, in front, for the flush to send them
+			w.mu.Lock()
+			w.pending = append(records, w.pending...)
+			w.mu.Unlock()
+			return
+		}
+		if err != nil {
+			w.mu.Lock()
+			if w.err == nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+		}
+		records = records[n:]
+	}
+}
+
+// send posts a batch of records, retrying while the error is temporary.
+// In the background, it yields instead of retrying when a flush is waiting.
+func (w *OTLPWriter) send(ctx context.Context, records [][]byte, maxRetries int, background bool) error {
+	body := w.requestBody(records)
+
+	backoff := w.minBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt >= maxRetries {
+			return fmt.Errorf("can't export %d log records: %w", len(records), err)
+		}
+
+		var yield, done <-chan struct{}
+		if background {
+			if w.flushing.Load() > 0 {
+				return errYield
+			}
+			yield, done = w.yield, w.done
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-yield:
+			timer.Stop()
+			return errYield
+		case <-done:
+			// don't keep the writer from closing, Close sends the records
+			timer.Stop()
+			return errYield
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("can't export %d log records: %w", len(records), err)
+		}
+		backoff = min(2*backoff, w.maxBackoff)
+	}
+}
+
+// post sends the body once, and tells whether a failure is worth retrying.
+func (w *OTLPWriter) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header = w.headers.Clone()
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// the collector may be restarting
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return true, errors.New(resp.Status)
+	default:
+		return false, errors.New(resp.Status)
+	}
+}
+
+// requestBody returns the body of an export request holding the records.
+func (w *OTLPWriter) requestBody(records [][]byte) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"resourceLogs":[{"resource":{"attributes":[`)
+	if w.serviceName != "" {
+		buf.WriteString(`{"key":"service.name","value":{"stringValue":`)
+		appendJSONString(&buf, w.serviceName)
+		buf.WriteString(`}}`)
+	}
+	buf.WriteString(`]},"scopeLogs":[{"scope":{"name":"pocketlog"},"logRecords":[`)
+
+	for i, record := range records {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(bytes.TrimSpace(record))
+	}
+
+	buf.WriteString(`]}]}]}`)
+
+	return buf.Bytes()
+}
+
+// takeErr returns the recorded error, and forgets it.
+func (w *OTLPWriter) takeErr() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.err
+	w.err = nil
+	return err
+}
@@ -0,0 +1,322 @@
+package pocketlog_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"learn-go-pockets/logger/pocketlog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// otlpRequest is the part of an OTLP/JSON export request the tests check.
+type otlpRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpAttribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			LogRecords []otlpRecord `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+type otlpRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           map[string]any  `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes"`
+	TraceID        string          `json:"traceId"`
+	SpanID         string          `json:"spanId"`
+}
+
+type otlpAttribute struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// collector is an OTLP/HTTP collector, answering with the given statuses in turn,
+// then with 200 OK.
+type collector struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []otlpRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		http.Error(w, "unexpected content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req otlpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.requests = append(c.requests, req)
+
+	if len(c.statuses) > 0 {
+		status := c.statuses[0]
+		c.statuses = c.statuses[1:]
+		w.WriteHeader(status)
+	}
+}
+
+// records returns the records received so far, per request.
+func (c *collector) records() [][]otlpRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var records [][]otlpRecord
+	for _, req := range c.requests {
+		records = append(records, req.ResourceLogs[0].ScopeLogs[0].LogRecords)
+	}
+	return records
+}
+
+func TestOTLPWriter(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	otlp := pocketlog.NewOTLPWriter(srv.URL, pocketlog.OTLPServiceName("bookworms"))
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithSink(otlp, pocketlog.LevelInfo, pocketlog.OTLPEncoder{}),
+		pocketlog.WithOutput(&testWriter{}),
+	)
+
+	ctx := pocketlog.ContextWithTraceID(context.Background(), "4BF92F3577B34DA6A3CE929D0E0E4736")
+	ctx = pocketlog.ContextWithSpanID(ctx, "00f067aa0ba902b7")
+	lgr.Named("loader").With(
+		pocketlog.Field{Key: "count", Value: 3},
+		pocketlog.Field{Key: "ratio", Value: 0.5},
+		pocketlog.Field{Key: "ok", Value: true},
+	).WarnfCtx(ctx, "%d shelves are missing", 2)
+
+	if err := otlp.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(c.requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(c.requests))
+	}
+
+	resource := c.requests[0].ResourceLogs[0].Resource.Attributes
+	if len(resource) != 1 || resource[0].Key != "service.name" || resource[0].Value["stringValue"] != "bookworms" {
+		t.Errorf("unexpected resource attributes %v", resource)
+	}
+
+	records := c.records()[0]
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	got := records[0]
+	if got.TimeUnixNano != "1741964966535897932" {
+		t.Errorf("expected time 1741964966535897932, got %s", got.TimeUnixNano)
+	}
+	if got.SeverityNumber != 13 || got.SeverityText != "WARN" {
+		t.Errorf("expected severity 13 WARN, got %d %s", got.SeverityNumber, got.SeverityText)
+	}
+	if got.Body["stringValue"] != "2 shelves are missing" {
+		t.Errorf("unexpected body %v", got.Body)
+	}
+	if got.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || got.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("unexpected trace and span IDs %s and %s", got.TraceID, got.SpanID)
+	}
+
+	expected := []otlpAttribute{
+		{Key: "logger.name", Value: map[string]any{"stringValue": "loader"}},
+		{Key: "count", Value: map[string]any{"intValue": "3"}},
+		{Key: "ratio", Value: map[string]any{"doubleValue": 0.5}},
+		{Key: "ok", Value: map[string]any{"boolValue": true}},
+	}
+	gotJSON, _ := json.Marshal(got.Attributes)
+	expectedJSON, _ := json.Marshal(expected)
+	if string(gotJSON) != string(expectedJSON) {
+		t.Errorf("expected attributes %s, got %s", expectedJSON, gotJSON)
+	}
+}
+
+func TestOTLPWriter_Batches(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	otlp := pocketlog.NewOTLPWriter(srv.URL, pocketlog.OTLPBatchSize(2), pocketlog.OTLPFlushInterval(time.Hour))
+	defer otlp.Close()
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(otlp), pocketlog.WithEncoder(pocketlog.OTLPEncoder{}))
+
+	for range 5 {
+		lgr.Infof(infoMessage)
+	}
+	if err := lgr.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	total := 0
+	for _, batch := range c.records() {
+		if len(batch) > 2 {
+			t.Errorf("expected batches of at most 2 records, got %d", len(batch))
+		}
+		total += len(batch)
+	}
+	if total != 5 {
+		t.Errorf("expected 5 records, got %d", total)
+	}
+}
+
+func TestOTLPWriter_Retry(t *testing.T) {
+	c := &collector{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	otlp := pocketlog.NewOTLPWriter(srv.URL, pocketlog.OTLPRetry(2, time.Millisecond, 2*time.Millisecond))
+	defer otlp.Close()
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(otlp), pocketlog.WithEncoder(pocketlog.OTLPEncoder{}))
+
+	lgr.Infof(infoMessage)
+	if err := lgr.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(c.records()); got != 3 {
+		t.Errorf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestOTLPWriter_Failure(t *testing.T) {
+	tests := map[string]struct {
+		statuses []int
+		attempts int
+	}{
+		"permanent":      {statuses: []int{http.StatusBadRequest}, attempts: 1},
+		"out of retries": {statuses: []int{502, 502, 502}, attempts: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &collector{statuses: tc.statuses}
+			srv := httptest.NewServer(c)
+			defer srv.Close()
+
+			otlp := pocketlog.NewOTLPWriter(srv.URL, pocketlog.OTLPRetry(2, time.Millisecond, time.Millisecond))
+			defer otlp.Close()
+			lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(otlp), pocketlog.WithEncoder(pocketlog.OTLPEncoder{}))
+
+			lgr.Errorf(errorMessage)
+			if err := lgr.Flush(); err == nil {
+				t.Errorf("expected an error")
+			}
+			if err := lgr.Flush(); err != nil {
+				t.Errorf("expected the error to be reported once, got %v", err)
+			}
+
+			if got := len(c.records()); got != tc.attempts {
+				t.Errorf("expected %d attempts, got %d", tc.attempts, got)
+			}
+		})
+	}
+}
+
+func TestOTLPWriter_NoFlushInterval(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	otlp := pocketlog.NewOTLPWriter(srv.URL, pocketlog.OTLPFlushInterval(0))
+	defer otlp.Close()
+
+	if _, err := otlp.Write([]byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := otlp.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(c.records()); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+}
+
+func TestOTLPWriter_FlushTimeout(t *testing.T) {
+	c := &collector{statuses: []int{503, 503, 503, 503, 503, 503}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	otlp := pocketlog.NewOTLPWriter(srv.URL, pocketlog.OTLPFlushTimeout(50*time.Millisecond))
+	defer otlp.Close()
+
+	if _, err := otlp.Write([]byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	if err := otlp.Flush(); err == nil {
+		t.Errorf("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Flush to give up after the timeout, took %v", elapsed)
+	}
+}
+
+func TestOTLPWriter_FlushDuringRetry(t *testing.T) {
+	c := &collector{statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	otlp := pocketlog.NewOTLPWriter(srv.URL, pocketlog.OTLPBatchSize(1), pocketlog.OTLPRetry(5, time.Hour, time.Hour))
+	defer otlp.Close()
+
+	if _, err := otlp.Write([]byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// wait for the background goroutine to fail, and to wait before retrying
+	for len(c.records()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	if err := otlp.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Flush not to wait for the background retry, took %v", elapsed)
+	}
+
+	if got := len(c.records()); got != 2 {
+		t.Errorf("expected the record to be sent again by Flush, got %d requests", got)
+	}
+}
+
+func TestOTLPWriter_QueueFull(t *testing.T) {
+	otlp := pocketlog.NewOTLPWriter("http://127.0.0.1:0", pocketlog.OTLPMaxQueue(1), pocketlog.OTLPFlushInterval(time.Hour))
+
+	if _, err := otlp.Write([]byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := otlp.Write([]byte(`{}`)); !errors.Is(err, pocketlog.ErrQueueFull) {
+		t.Errorf("expected %v, got %v", pocketlog.ErrQueueFull, err)
+	}
+	if otlp.Dropped() != 1 {
+		t.Errorf("expected 1 dropped record, got %d", otlp.Dropped())
+	}
+
+	// the collector can't be reached
+	if err := otlp.Close(); err == nil {
+		t.Errorf("expected an error")
+	}
+	if _, err := otlp.Write([]byte(`{}`)); !errors.Is(err, pocketlog.ErrClosed) {
+		t.Errorf("expected %v, got %v", pocketlog.ErrClosed, err)
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Package pocketlogtest helps testing code that logs with pocketlog.
+
+NewLogger returns a logger whose entries are captured by a Recorder,
+which gives access to them, and checks expectations against them:
+
+	lgr, rec := pocketlogtest.NewLogger(t, pocketlog.LevelDebug)
+	loadShelves(lgr)
+	rec.ExpectError("shelf not found")
+
+When the test fails, the captured entries are written to its log.
+*/
+package pocketlogtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"learn-go-pockets/logger/pocketlog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Entry is an entry captured by a Recorder.
+type Entry struct {
+	// Time is the zero time if the logger used a custom time format.
+	Time    time.Time
+	Level   pocketlog.Level
+	Message string
+	Logger  string
+	Caller  string
+	Func    string
+	// Fields holds the fields of the entry, as decoded from JSON:
+	// numbers are float64, objects are map[string]any.
+	Fields map[string]any
+	// Raw is the line written by the logger.
+	Raw string
+}
+
+// Field returns the value of the field with the given key, if there is one.
+func (e Entry) Field(key string) (any, bool) {
+	v, ok := e.Fields[key]
+	return v, ok
+}
+
+// HasField tells whether the entry has a field with the given key and value.
+// The value is compared as it would be written in JSON, so that
+// HasField("count", 3) holds for a field logged as the int 3.
+func (e Entry) HasField(key string, value any) bool {
+	got, ok := e.Fields[key]
+	if !ok {
+		return false
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	var expected any
+	if err := json.Unmarshal(b, &expected); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(got, expected)
+}
+
+// Recorder is an io.Writer that captures the JSON entries written by a logger.
+// It is safe for concurrent use.
+type Recorder struct {
+	tb testing.TB
+
+	mu      sync.Mutex
+	pending []byte
+	entries []Entry
+}
+
+// NewRecorder returns a Recorder reporting to tb. If the test fails,
+// the captured entries are written to its log when it ends.
+func NewRecorder(tb testing.TB) *Recorder {
+	r := &Recorder{tb: tb}
+
+	tb.Cleanup(func() {
+		if tb.Failed() {
+			tb.Logf("captured log entries:\n%s", r.dump())
+		}
+	})
+
+	return r
+}
+
+// NewLogger returns a logger at the given threshold, configured with opts,
+// and the Recorder capturing its entries. The output and the encoder of the
+// logger are always those of the Recorder.
+func NewLogger(tb testing.TB, threshold pocketlog.Level, opts ...pocketlog.Option) (*pocketlog.Logger, *Recorder) {
+	r := NewRecorder(tb)
+
+	// don't append to opts, which may share its array with the caller
+	opts = slices.Concat(opts, []pocketlog.Option{pocketlog.WithOutput(r), pocketlog.WithEncoder(pocketlog.JSONEncoder{})})
+	return pocketlog.New(threshold, opts...), r
+}
+
+// Write implements the io.Writer interface. Every complete line is decoded as an entry;
+// a line that isn't a JSON object fails the test.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = append(r.pending, p...)
+	for {
+		i := bytes.IndexByte(r.pending, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := string(r.pending[:i])
+		r.pending = r.pending[i+1:]
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		entry, err := decode(line)
+		if err != nil {
+			r.tb.Errorf("pocketlogtest: can't decode entry %q: %v", line, err)
+			continue
+		}
+		r.entries = append(r.entries, entry)
+	}
+
+	return len(p), nil
+}
+
+// Entries returns a copy of the entries captured so far.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Entry(nil), r.entries...)
+}
+
+// EntriesAt returns the entries captured so far at the given level.
+func (r *Recorder) EntriesAt(lvl pocketlog.Level) []Entry {
+	var entries []Entry
+	for _, e := range r.Entries() {
+		if e.Level == lvl {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Reset forgets the entries captured so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+	r.pending = nil
+}
+
+// ExpectEntry fails the test if no entry was captured at the given level
+// with a message containing substr. It returns the first matching entry.
+func (r *Recorder) ExpectEntry(lvl pocketlog.Level, substr string) Entry {
+	r.tb.Helper()
+
+	for _, e := range r.EntriesAt(lvl) {
+		if strings.Contains(e.Message, substr) {
+			return e
+		}
+	}
+
+	r.tb.Errorf("expected an entry at level %s containing %q, got none", lvl, substr)
+	return Entry{}
+}
+
+// ExpectError fails the test if no error entry was captured
+// with a message containing substr. It returns the first matching entry.
+func (r *Recorder) ExpectError(substr string) Entry {
+	r.tb.Helper()
+
+	return r.ExpectEntry(pocketlog.LevelError, substr)
+}
+
+// ExpectNoEntry fails the test if an entry was captured at the given level.
+func (r *Recorder) ExpectNoEntry(lvl pocketlog.Level) {
+	r.tb.Helper()
+
+	if entries := r.EntriesAt(lvl); len(entries) > 0 {
+		r.tb.Errorf("expected no entry at level %s, got %d, starting with %q", lvl, len(entries), entries[0].Message)
+	}
+}
+
+// ExpectCount fails the test if the number of captured entries isn't n.
+func (r *Recorder) ExpectCount(n int) {
+	r.tb.Helper()
+
+	if got := len(r.Entries()); got != n {
+		r.tb.Errorf("expected %d entries, got %d", n, got)
+	}
+}
+
+// dump returns the captured lines.
+func (r *Recorder) dump() string {
+	var b strings.Builder
+	for _, e := range r.Entries() {
+		b.WriteString(e.Raw)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// decode reads an entry from a JSON line.
+func decode(line string) (Entry, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{Raw: line, Fields: fields}
+
+	if lvl, ok := fields["level"].(string); ok {
+		parsed, err := pocketlog.ParseLevel(lvl)
+		if err != nil {
+			return Entry{}, err
+		}
+		entry.Level = parsed
+	}
+
+	switch t := fields["time"].(type) {
+	case string:
+		entry.Time, _ = time.Parse(time.RFC3339Nano, t)
+	case float64:
+		entry.Time = time.UnixMilli(int64(t))
+	}
+
+	entry.Message, _ = fields["message"].(string)
+	entry.Logger, _ = fields["logger"].(string)
+	entry.Caller, _ = fields["caller"].(string)
+	entry.Func, _ = fields["func"].(string)
+
+	for _, key := range []string{"time", "level", "message", "logger", "caller", "func"} {
+		delete(fields, key)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,174 @@
+package pocketlogtest_test
+
+import (
+	"fmt"
+	"learn-go-pockets/logger/pocketlog"
+	"learn-go-pockets/logger/pocketlog/pocketlogtest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t, pocketlog.LevelInfo,
+		pocketlog.WithClock(func() time.Time { return time.UnixMilli(1741964966535) }),
+		pocketlog.WithEncoder(pocketlog.TextEncoder{}), // overridden by the recorder
+	)
+
+	lgr.Debugf("hidden")
+	lgr.With(
+		pocketlog.Field{Key: "shelf", Value: "sci-fi"},
+		pocketlog.Field{Key: "count", Value: 3},
+	).Infof("Shelf loaded")
+	lgr.Errorf("Shelf %s not found", "fantasy")
+
+	rec.ExpectCount(2)
+	rec.ExpectNoEntry(pocketlog.LevelDebug)
+	rec.ExpectError("not found")
+
+	entry := rec.ExpectEntry(pocketlog.LevelInfo, "loaded")
+	if !entry.HasField("shelf", "sci-fi") || !entry.HasField("count", 3) {
+		t.Errorf("expected the fields of the entry, got %v", entry.Fields)
+	}
+
+	if entry.HasField("count", "3") || entry.HasField("missing", nil) {
+		t.Errorf("expected field values to be typed")
+	}
+
+	if _, ok := entry.Field("message"); ok {
+		t.Errorf("expected fields not to hold the message")
+	}
+
+	if !entry.Time.Equal(time.UnixMilli(1741964966535)) {
+		t.Errorf("expected the time of the entry, got %v", entry.Time)
+	}
+
+	rec.Reset()
+	rec.ExpectCount(0)
+}
+
+func TestNewLogger_KeepsOptions(t *testing.T) {
+	// the options passed have room to append to
+	spare := pocketlog.WithClock(time.Now)
+	opts := []pocketlog.Option{pocketlog.WithCaller(0), spare, spare}
+
+	lgr, rec := pocketlogtest.NewLogger(t, pocketlog.LevelInfo, opts[:1]...)
+	lgr.Infof("loaded")
+	rec.ExpectEntry(pocketlog.LevelInfo, "loaded")
+
+	for _, opt := range opts[1:] {
+		if fmt.Sprintf("%p", opt) != fmt.Sprintf("%p", spare) {
+			t.Error("expected the options of the caller to be left untouched")
+		}
+	}
+}
+
+func TestRecorder_Failures(t *testing.T) {
+	tests := map[string]struct {
+		check    func(rec *pocketlogtest.Recorder)
+		expected string
+	}{
+		"missing error": {
+			check:    func(rec *pocketlogtest.Recorder) { rec.ExpectError("on fire") },
+			expected: `expected an entry at level error containing "on fire", got none`,
+		},
+		"unexpected entry": {
+			check:    func(rec *pocketlogtest.Recorder) { rec.ExpectNoEntry(pocketlog.LevelInfo) },
+			expected: `expected no entry at level info, got 1, starting with "Shelf loaded"`,
+		},
+		"wrong count": {
+			check:    func(rec *pocketlogtest.Recorder) { rec.ExpectCount(3) },
+			expected: "expected 3 entries, got 1",
+		},
+		"undecodable line": {
+			check: func(rec *pocketlogtest.Recorder) {
+				_, _ = rec.Write([]byte("not json\n"))
+			},
+			expected: `pocketlogtest: can't decode entry "not json"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ftb := &fakeTB{TB: t}
+
+			lgr, rec := pocketlogtest.NewLogger(ftb, pocketlog.LevelInfo)
+			lgr.Infof("Shelf loaded")
+
+			tc.check(rec)
+
+			if !ftb.Failed() {
+				t.Fatalf("expected the check to fail")
+			}
+
+			if !strings.HasPrefix(ftb.errors[0], tc.expected) {
+				t.Errorf("expected error %q, got %q", tc.expected, ftb.errors[0])
+			}
+
+			ftb.cleanup()
+			if len(ftb.logs) != 1 || !strings.Contains(ftb.logs[0], `"message":"Shelf loaded"`) {
+				t.Errorf("expected the entries to be dumped on failure, got %v", ftb.logs)
+			}
+		})
+	}
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+	lgr, rec := pocketlogtest.NewLogger(t, pocketlog.LevelInfo)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 25 {
+				lgr.Infof("entry %d", i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	rec.ExpectCount(100)
+}
+
+// fakeTB is a testing.TB recording failures instead of reporting them.
+type fakeTB struct {
+	testing.TB
+
+	failed   bool
+	errors   []string
+	logs     []string
+	cleanups []func()
+}
+
+// Helper implements the testing.TB interface.
+func (f *fakeTB) Helper() {}
+
+// Errorf implements the testing.TB interface.
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.failed = true
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// Logf implements the testing.TB interface.
+func (f *fakeTB) Logf(format string, args ...any) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+// Failed implements the testing.TB interface.
+func (f *fakeTB) Failed() bool {
+	return f.failed
+}
+
+// Cleanup implements the testing.TB interface.
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// cleanup runs the registered cleanup functions.
+func (f *fakeTB) cleanup() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package pocketlog_test
+
+func init() {
+	raceEnabled = true
+}
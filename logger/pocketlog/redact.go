@@ -0,0 +1,324 @@
+package pocketlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Redactor is implemented by types holding sensitive values.
+// Whenever such a value is logged, as an argument of a message or as the value
+// of a field, what Redact returns is logged instead.
+type Redactor interface {
+	Redact() any
+}
+
+// Redacted replaces the values hidden by the redaction.
+const Redacted = "[REDACTED]"
+
+// redactor hides sensitive values from entries.
+// The nil redactor only replaces values implementing Redactor.
+type redactor struct {
+	// keys holds the lowercase keys of the fields to redact.
+	keys     map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// resolveArgs returns the arguments of a message, where Lazy values are computed
+// and values implementing Redactor are replaced.
+// The arguments are only copied if one was replaced.
+func resolveArgs(args []any) []any {
+	resolved := args
+	copied := false
+	for i, arg := range args {
+		value, replaced := arg, false
+		if lazy, ok := value.(Lazy); ok {
+			value, replaced = lazy(), true
+		}
+		if r, ok := value.(Redactor); ok {
+			value, replaced = r.Redact(), true
+		}
+
+		if !replaced {
+			continue
+		}
+
+		if !copied {
+			resolved = append([]any(nil), args...)
+			copied = true
+		}
+		resolved[i] = value
+	}
+
+	return resolved
+}
+
+// redact hides the sensitive values of the entry:
+// the parts of its message matching the patterns,
+// and the fields with a denied key, a value implementing Redactor,
+// or a value whose text matches the patterns. Within structs and maps,
+// the values of denied keys are hidden too.
+func (r *redactor) redact(e *Entry) {
+	if r != nil {
+		e.Message = r.redactString(e.Message)
+	}
+
+	fields := e.Fields
+	copied := false
+	for i, f := range e.Fields {
+		value, ok := r.redactValue(f.Key, f.Value)
+		if !ok {
+			continue
+		}
+
+		// fields are shared with the logger, copy them before editing them
+		if !copied {
+			fields = append([]Field(nil), e.Fields...)
+			copied = true
+		}
+		fields[i].Value = value
+	}
+	e.Fields = fields
+}
+
+// redactValue returns what should be logged instead of the value of the field,
+// and whether it differs from the value.
+func (r *redactor) redactValue(key string, value any) (any, bool) {
+	if group, ok := value.(Fields); ok {
+		return r.redactGroup(key, group)
+	}
+
+	redacted := false
+	if red, ok := value.(Redactor); ok {
+		value = red.Redact()
+		redacted = true
+	}
+
+	if r == nil {
+		return value, redacted
+	}
+
+	if r.deniedKey(key) {
+		return Redacted, true
+	}
+
+	if hidden, ok := r.redactStructured(value); ok {
+		return hidden, true
+	}
+
+	return value, redacted
+}
+
+// redactStructured returns the value where the parts of its text matching the
+// patterns are hidden, as well as the values of denied keys within structs and maps,
+// and whether anything was hidden. Structs and maps are returned as JSON.
+func (r *redactor) redactStructured(value any) (any, bool) {
+	switch v := value.(type) {
+	case nil, bool:
+		return value, false
+	case string:
+		hidden := r.redactString(v)
+		return hidden, hidden != v
+	case errorValue:
+		if v.err == nil || len(r.patterns) == 0 {
+			return value, false
+		}
+		// the message of the error and of its causes are hidden when encoded
+		v.redactor = r
+		return v, true
+	case error:
+		return r.redactText(v.Error())
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64,
+		time.Duration, time.Time:
+		if len(r.patterns) == 0 {
+			return value, false
+		}
+		return r.redactText(valueText(v))
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return r.redactText(fmt.Sprint(value))
+	}
+
+	var buf bytes.Buffer
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if hidden, err := r.redactJSON(&buf, dec); err == nil && hidden {
+		return json.RawMessage(buf.Bytes()), true
+	}
+
+	// text encoders write what String returns, which may differ from the JSON
+	if s, ok := value.(fmt.Stringer); ok && len(r.patterns) > 0 {
+		return r.redactText(s.String())
+	}
+
+	return value, false
+}
+
+// redactText returns the text where the parts matching the patterns are hidden,
+// and whether one was.
+func (r *redactor) redactText(text string) (any, bool) {
+	hidden := r.redactString(text)
+	return hidden, hidden != text
+}
+
+// redactJSON copies the next JSON value of dec to buf, hiding the values of denied
+// keys and the parts of strings and numbers matching the patterns.
+// It tells whether anything was hidden.
+func (r *redactor) redactJSON(buf *bytes.Buffer, dec *json.Decoder) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			return r.redactJSONArray(buf, dec)
+		}
+		return r.redactJSONObject(buf, dec)
+	case string:
+		hidden := r.redactString(tok)
+		appendJSONString(buf, hidden)
+		return hidden != tok, nil
+	case json.Number:
+		if hidden := r.redactString(tok.String()); hidden != tok.String() {
+			appendJSONString(buf, hidden)
+			return true, nil
+		}
+		buf.WriteString(tok.String())
+	case bool:
+		buf.WriteString(strconv.FormatBool(tok))
+	default:
+		buf.WriteString("null")
+	}
+
+	return false, nil
+}
+
+// redactJSONObject copies the members of a JSON object, once its opening brace was read.
+func (r *redactor) redactJSONObject(buf *bytes.Buffer, dec *json.Decoder) (bool, error) {
+	hidden := false
+	buf.WriteByte('{')
+	for i := 0; dec.More(); i++ {
+		tok, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		key, _ := tok.(string)
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		appendJSONString(buf, key)
+		buf.WriteByte(':')
+
+		if r.deniedKey(key) {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return false, err
+			}
+			appendJSONString(buf, Redacted)
+			hidden = true
+			continue
+		}
+
+		ok, err := r.redactJSON(buf, dec)
+		if err != nil {
+			return false, err
+		}
+		hidden = hidden || ok
+	}
+	buf.WriteByte('}')
+
+	// the closing brace
+	_, err := dec.Token()
+	return hidden, err
+}
+
+// redactJSONArray copies the items of a JSON array, once its opening bracket was read.
+func (r *redactor) redactJSONArray(buf *bytes.Buffer, dec *json.Decoder) (bool, error) {
+	hidden := false
+	buf.WriteByte('[')
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		ok, err := r.redactJSON(buf, dec)
+		if err != nil {
+			return false, err
+		}
+		hidden = hidden || ok
+	}
+	buf.WriteByte(']')
+
+	// the closing bracket
+	_, err := dec.Token()
+	return hidden, err
+}
+
+// redactGroup returns the fields of a group, where the sensitive values are hidden,
+// and whether one of them was. The fields are only copied if so.
+func (r *redactor) redactGroup(key string, group Fields) (any, bool) {
+	if r != nil && r.deniedKey(key) {
+		return Redacted, true
+	}
+
+	redacted := group
+	copied := false
+	for i, f := range group {
+		value, ok := r.redactValue(key+"."+f.Key, f.Value)
+		if !ok {
+			continue
+		}
+
+		if !copied {
+			redacted = append(Fields(nil), group...)
+			copied = true
+		}
+		redacted[i].Value = value
+	}
+
+	return redacted, copied
+}
+
+// deniedKey tells whether the key, or its last dotted segment, is denied.
+func (r *redactor) deniedKey(key string) bool {
+	key = strings.ToLower(key)
+	if r.keys[key] {
+		return true
+	}
+
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		return r.keys[key[i+1:]]
+	}
+
+	return false
+}
+
+// redactString replaces the parts of s matching the patterns.
+// The nil redactor returns s.
+func (r *redactor) redactString(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, p := range r.patterns {
+		s = p.ReplaceAllLiteralString(s, Redacted)
+	}
+	return s
+}
+
+// redactorToConfigure returns the redactor of the logger, creating it if needed.
+// It is only meant to be called by configuration functions.
+func (l *Logger) redactorToConfigure() *redactor {
+	if l.redactor == nil {
+		l.redactor = &redactor{keys: make(map[string]bool)}
+	}
+	return l.redactor
+}
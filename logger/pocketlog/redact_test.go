@@ -0,0 +1,151 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"fmt"
+	"learn-go-pockets/logger/pocketlog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// apiToken is a secret that knows how to hide itself.
+type apiToken string
+
+// Redact implements the pocketlog.Redactor interface.
+func (t apiToken) Redact() any {
+	if len(t) < 4 {
+		return pocketlog.Redacted
+	}
+	return string(t[:4]) + "…"
+}
+
+// user is a struct holding sensitive values, that doesn't implement pocketlog.Redactor.
+type user struct {
+	Name  string
+	Email string
+}
+
+var emailPattern = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+
+func TestLogger_Redaction(t *testing.T) {
+	tests := map[string]struct {
+		opts     []pocketlog.Option
+		log      func(lgr *pocketlog.Logger)
+		expected string
+	}{
+		"redactor argument": {
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Infof("calling with %v", apiToken("sk_live_123"))
+			},
+			expected: `"message":"calling with sk_l…"}`,
+		},
+		"redactor field": {
+			log: func(lgr *pocketlog.Logger) {
+				lgr.With(pocketlog.Field{Key: "token", Value: apiToken("abc")}).Infof("calling")
+			},
+			expected: `"message":"calling","token":"[REDACTED]"}`,
+		},
+		"denied keys": {
+			opts: []pocketlog.Option{pocketlog.WithRedactedKeys("password", "Authorization")},
+			log: func(lgr *pocketlog.Logger) {
+				lgr.With(
+					pocketlog.Field{Key: "user", Value: "ada"},
+					pocketlog.Field{Key: "Password", Value: "hunter2"},
+					pocketlog.Field{Key: "headers.authorization", Value: []string{"Bearer x"}},
+				).Infof("login")
+			},
+			expected: `"message":"login","user":"ada","Password":"[REDACTED]","headers.authorization":"[REDACTED]"}`,
+		},
+		"patterns in message": {
+			opts: []pocketlog.Option{pocketlog.WithRedactedPatterns(emailPattern)},
+			log: func(lgr *pocketlog.Logger) {
+				lgr.Infof("new user %v", user{Name: "Ada", Email: "ada@example.com"})
+			},
+			expected: `"message":"new user {Ada [REDACTED]}"}`,
+		},
+		"patterns in fields": {
+			opts: []pocketlog.Option{pocketlog.WithRedactedPatterns(emailPattern)},
+			log: func(lgr *pocketlog.Logger) {
+				lgr.With(
+					pocketlog.Field{Key: "contact", Value: "write to ada@example.com"},
+					pocketlog.Field{Key: "tags", Value: []string{"a"}},
+				).Infof("hello")
+			},
+			expected: `"message":"hello","contact":"write to [REDACTED]","tags":["a"]}`,
+		},
+		"patterns in structs": {
+			opts: []pocketlog.Option{pocketlog.WithRedactedPatterns(emailPattern)},
+			log: func(lgr *pocketlog.Logger) {
+				lgr.With(
+					pocketlog.Any("user", user{Name: "Ada", Email: "ada@example.com"}),
+					pocketlog.Any("team", []user{{Name: "Bob", Email: "bob@example.com"}}),
+				).Infof("hello")
+			},
+			expected: `"message":"hello","user":{"Name":"Ada","Email":"[REDACTED]"},"team":[{"Name":"Bob","Email":"[REDACTED]"}]}`,
+		},
+		"denied keys in maps": {
+			opts: []pocketlog.Option{pocketlog.WithRedactedKeys("password")},
+			log: func(lgr *pocketlog.Logger) {
+				lgr.With(pocketlog.Any("creds", map[string]any{
+					"login":    "ada",
+					"password": "hunter2",
+					"backup":   map[string]string{"password": "hunter3"},
+				})).Infof("login")
+			},
+			expected: `"message":"login","creds":{"backup":{"password":"[REDACTED]"},"login":"ada","password":"[REDACTED]"}}`,
+		},
+		"patterns in errors": {
+			opts: []pocketlog.Option{pocketlog.WithRedactedPatterns(emailPattern)},
+			log: func(lgr *pocketlog.Logger) {
+				err := fmt.Errorf("can't notify: %w", errors.New("unknown ada@example.com"))
+				lgr.With(pocketlog.Err(err), pocketlog.Any("cause", errors.Unwrap(err))).Infof("failed")
+			},
+			expected: `"message":"failed","error":{"message":"can't notify: unknown [REDACTED]","type":"*fmt.wrapError",` +
+				`"causes":[{"message":"unknown [REDACTED]","type":"*errors.errorString"}]},"cause":"unknown [REDACTED]"}`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tw := &testWriter{}
+
+			opts := append([]pocketlog.Option{pocketlog.WithOutput(tw)}, tc.opts...)
+			tc.log(pocketlog.New(pocketlog.LevelInfo, opts...))
+
+			got := strings.TrimSuffix(tw.contents, "\n")
+			if !strings.HasSuffix(got, tc.expected) {
+				t.Errorf("expected %s to end with %s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLogger_RedactionOnEveryEntry(t *testing.T) {
+	tw := &testWriter{}
+
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithRedactedKeys("secret")).
+		With(pocketlog.Field{Key: "secret", Value: "s3cr3t"})
+
+	lgr.Infof("first")
+	lgr.Infof("second")
+
+	if strings.Count(tw.contents, `"secret":"[REDACTED]"`) != 2 {
+		t.Errorf("expected both entries to be redacted, got %s", tw.contents)
+	}
+}
+
+func TestLogger_RedactionOfErrorsInText(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithRedactedPatterns(emailPattern),
+	)
+
+	lgr.With(pocketlog.Err(errors.New("unknown ada@example.com"))).Infof("failed")
+
+	if !strings.HasSuffix(tw.contents, ` error="unknown [REDACTED]"`+"\n") {
+		t.Errorf("expected the error message to be redacted, got %s", tw.contents)
+	}
+}
@@ -0,0 +1,421 @@
+package pocketlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the layout of the time in the name of backups.
+// It sorts chronologically, and has no colon for the sake of Windows.
+const backupTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// compressSuffix is the extension added to compressed backups.
+const compressSuffix = ".gz"
+
+// RotatingFile is an io.WriteCloser writing to a file that is rotated once it
+// grows too big or too old. The rotated file is renamed as a backup, with the
+// time of the rotation in its name, and the writes go on in a new file.
+// Backups can be compressed, and the oldest ones are removed.
+//
+// A RotatingFile is safe for concurrent use.
+type RotatingFile struct {
+	filename   string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+	clock      func() time.Time
+
+	// mu protects the file, and what we know of it. The file is nil while it
+	// can't be opened, and opening it is tried again on the next write.
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+	// grownFrom is the size the file must grow from to be rotated again,
+	// after it couldn't be renamed.
+	grownFrom int64
+
+	// millMu serialises the compression and removal of backups,
+	// which run in the background, tracked by mill.
+	millMu  sync.Mutex
+	mill    sync.WaitGroup
+	millErr error
+
+	// signals receives the signals requesting the file to be reopened.
+	signals chan os.Signal
+	done    chan struct{}
+}
+
+// RotateOption defines a functional option to a RotatingFile.
+type RotateOption func(*RotatingFile)
+
+// RotateMaxSize returns a configuration function that rotates the file
+// before it grows over size bytes. A single write bigger than size
+// still makes it to a file of its own.
+func RotateMaxSize(size int64) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxSize = size
+	}
+}
+
+// RotateMaxAge returns a configuration function that rotates the file
+// once it was opened for longer than age.
+func RotateMaxAge(age time.Duration) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxAge = age
+	}
+}
+
+// RotateMaxBackups returns a configuration function that keeps at most n backups,
+// removing the oldest ones. All backups are kept by default.
+func RotateMaxBackups(n int) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxBackups = n
+	}
+}
+
+// RotateCompress returns a configuration function that compresses backups with gzip,
+// in the background.
+func RotateCompress() RotateOption {
+	return func(f *RotatingFile) {
+		f.compress = true
+	}
+}
+
+// NewRotatingFile opens, or creates, the file to write to. Writes are appended to
+// the file if it already exists. Without options, the file is never rotated.
+func NewRotatingFile(filename string, opts ...RotateOption) (*RotatingFile, error) {
+	f := &RotatingFile{
+		filename: filename,
+		clock:    time.Now,
+		done:     make(chan struct{}),
+	}
+
+	for _, configFunc := range opts {
+		configFunc(f)
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write implements the io.Writer interface.
+// It rotates the file first if the write would make it too big, or if it is too old.
+// If the file couldn't be opened again after a rotation, it tries once more.
+// If it couldn't be renamed, p is written to it all the same, the error is
+// returned, and the rotation is only tried again once the file grew by its
+// maximum size, or got too old, once more.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, ErrClosed
+	}
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	var rotateErr error
+	if f.size > 0 && f.mustRotate(len(p)) {
+		rotateErr = f.rotate()
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, errors.Join(rotateErr, err)
+}
+
+// Rotate closes the current file, renames it as a backup, and opens a new one.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrClosed
+	}
+
+	return f.rotate()
+}
+
+// Reopen closes the current file and opens it again by its name. Use it when
+// an external tool, such as logrotate, moved the file away.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrClosed
+	}
+
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+
+	return errors.Join(err, f.open())
+}
+
+// ReopenOnSignal reopens the file whenever the process receives one of the signals,
+// usually syscall.SIGHUP, until the file is closed. It should only be called once.
+func (f *RotatingFile) ReopenOnSignal(sigs ...os.Signal) {
+	f.signals = make(chan os.Signal, 1)
+	signal.Notify(f.signals, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-f.signals:
+				_ = f.Reopen()
+			case <-f.done:
+				return
+			}
+		}
+	}()
+}
+
+// Close closes the file, and waits for the backups to be compressed and removed.
+// It returns the first error met while doing so in the background.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return ErrClosed
+	}
+
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.closed = true
+	f.mu.Unlock()
+
+	if f.signals != nil {
+		signal.Stop(f.signals)
+	}
+	close(f.done)
+
+	f.mill.Wait()
+
+	return errors.Join(err, f.millErr)
+}
+
+// mustRotate tells whether the file must be rotated before writing n bytes.
+func (f *RotatingFile) mustRotate(n int) bool {
+	if f.maxSize > 0 && f.size-f.grownFrom+int64(n) > f.maxSize {
+		return true
+	}
+
+	return f.maxAge > 0 && f.clock().Sub(f.openedAt) >= f.maxAge
+}
+
+// open opens the file for appending, and records its size.
+// The caller must hold the lock.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.filename), 0o755); err != nil {
+		return fmt.Errorf("can't create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("can't open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("can't stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.grownFrom = 0
+	f.openedAt = f.clock()
+
+	return nil
+}
+
+// rotate renames the file as a backup, opens a new one, and starts
+// compressing and removing backups in the background. If the file can't
+// be renamed, the writes go on in it.
+// The caller must hold the lock.
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		err := f.file.Close()
+		f.file = nil
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(f.filename, f.backupName(f.clock())); err != nil {
+		err = fmt.Errorf("can't rename log file: %w", err)
+		if openErr := f.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+
+		// don't try again on every write
+		f.grownFrom = f.size
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.mill.Add(1)
+	go func() {
+		defer f.mill.Done()
+		f.millBackups()
+	}()
+
+	return nil
+}
+
+// backupName returns the name of the backup of a file rotated at t,
+// e.g. app-2025-03-14T15-09-26.535897932.log for app.log.
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.filename)
+	prefix := strings.TrimSuffix(f.filename, ext)
+	return prefix + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// millBackups compresses the backups if required, and removes the oldest ones.
+func (f *RotatingFile) millBackups() {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	backups, err := f.backups()
+	if err != nil {
+		f.recordMillErr(err)
+		return
+	}
+
+	if f.maxBackups > 0 && len(backups) > f.maxBackups {
+		for _, name := range backups[:len(backups)-f.maxBackups] {
+			if err := os.Remove(name); err != nil {
+				f.recordMillErr(err)
+			}
+		}
+		backups = backups[len(backups)-f.maxBackups:]
+	}
+
+	if !f.compress {
+		return
+	}
+
+	for _, name := range backups {
+		if strings.HasSuffix(name, compressSuffix) {
+			continue
+		}
+
+		if err := compressFile(name); err != nil {
+			f.recordMillErr(err)
+		}
+	}
+}
+
+// recordMillErr keeps the first error met in the background.
+// The caller must hold the mill lock.
+func (f *RotatingFile) recordMillErr(err error) {
+	if f.millErr == nil {
+		f.millErr = err
+	}
+}
+
+// backups returns the names of the backups of the file, from the oldest to the newest.
+func (f *RotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(f.filename)
+	prefix := strings.TrimSuffix(f.filename, ext) + "-"
+
+	matches, err := filepath.Glob(globEscape(prefix) + "*" + globEscape(ext) + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, name := range matches {
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)
+		stamp = strings.TrimPrefix(stamp, prefix)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, name)
+		}
+	}
+
+	// the time in the names sorts chronologically
+	slices.Sort(backups)
+
+	return backups, nil
+}
+
+// globEscape escapes the characters with a meaning in filepath.Match patterns.
+func globEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[\`, r) && filepath.Separator != '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// compressFile replaces the file by its gzipped version.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(dst.Name())
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	_ = src.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,334 @@
+package pocketlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_MaxSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	f, err := NewRotatingFile(filename, RotateMaxSize(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.clock = tickingClock(time.Second)
+
+	for _, line := range []string{"1234\n", "5678\n", "abcde\n", "a line longer than 10\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	assertFile(t, filename, "a line longer than 10\n")
+
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"1234\n5678\n", "abcde\n"}
+	if len(backups) != len(expected) {
+		t.Fatalf("expected %d backups, got %v", len(expected), backups)
+	}
+
+	for i, name := range backups {
+		assertFile(t, name, expected[i])
+	}
+}
+
+func TestRotatingFile_MaxAge(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	now := time.Date(2025, time.March, 14, 15, 9, 26, 0, time.UTC)
+	f, err := NewRotatingFile(filename, RotateMaxAge(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.clock = func() time.Time { return now }
+	f.openedAt = now
+
+	_, _ = f.Write([]byte("morning\n"))
+	now = now.Add(59 * time.Minute)
+	_, _ = f.Write([]byte("still morning\n"))
+	now = now.Add(time.Minute)
+	_, _ = f.Write([]byte("afternoon\n"))
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	assertFile(t, filename, "afternoon\n")
+	assertFile(t, filepath.Join(dir, "app-2025-03-14T16-09-26.000000000.log"), "morning\nstill morning\n")
+}
+
+func TestRotatingFile_MaxBackupsAndCompression(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	// a file with a similar name must be left alone
+	unrelated := filepath.Join(dir, "app-notes.log")
+	if err := os.WriteFile(unrelated, []byte("notes"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := NewRotatingFile(filename, RotateMaxBackups(2), RotateCompress())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f.clock = tickingClock(time.Second)
+
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		_, _ = f.Write([]byte(line))
+		if err := f.Rotate(); err != nil {
+			t.Fatalf("unexpected rotate error: %v", err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"three\n", "four\n"}
+	if len(backups) != len(expected) {
+		t.Fatalf("expected %d backups, got %v", len(expected), backups)
+	}
+
+	for i, name := range backups {
+		if !strings.HasSuffix(name, ".log.gz") {
+			t.Errorf("expected %s to be compressed", name)
+			continue
+		}
+		assertGzipFile(t, name, expected[i])
+	}
+
+	assertFile(t, unrelated, "notes")
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	f, err := NewRotatingFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	_, _ = f.Write([]byte("before logrotate\n"))
+
+	// an external tool moves the file away
+	moved := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(filename, moved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("unexpected reopen error: %v", err)
+	}
+
+	_, _ = f.Write([]byte("after logrotate\n"))
+
+	assertFile(t, moved, "before logrotate\n")
+	assertFile(t, filename, "after logrotate\n")
+}
+
+func TestRotatingFile_AppendsAndCloses(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "logs", "app.log")
+
+	for _, line := range []string{"first run\n", "second run\n"} {
+		f, err := NewRotatingFile(filename, RotateMaxSize(100))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, _ = f.Write([]byte(line))
+
+		if err := f.Close(); err != nil {
+			t.Fatalf("unexpected close error: %v", err)
+		}
+
+		if _, err := f.Write([]byte("too late\n")); err != ErrClosed {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+	}
+
+	assertFile(t, filename, "first run\nsecond run\n")
+}
+
+func TestRotatingFile_RecoversFromReopen(t *testing.T) {
+	dir := t.TempDir()
+	logs := filepath.Join(dir, "logs")
+	filename := filepath.Join(logs, "app.log")
+
+	f, err := NewRotatingFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	// the directory is replaced by a file, the log file can't be opened
+	if err := os.RemoveAll(logs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(logs, nil, 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.Reopen(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := f.Write([]byte("lost\n")); err == nil {
+		t.Error("expected an error")
+	}
+
+	if err := os.Remove(logs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := f.Write([]byte("recovered\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.Write([]byte("reopened\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertFile(t, filename, "recovered\nreopened\n")
+}
+
+func TestRotatingFile_RecoversFromRename(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	now := time.Date(2025, time.March, 14, 15, 9, 26, 0, time.UTC)
+	f, err := NewRotatingFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	f.clock = func() time.Time { return now }
+
+	_, _ = f.Write([]byte("before\n"))
+
+	// a directory stands where the backup goes, the file can't be renamed
+	backup := f.backupName(now)
+	if err := os.MkdirAll(filepath.Join(backup, "taken"), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := f.Rotate(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := f.Write([]byte("after\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertFile(t, filename, "before\nafter\n")
+}
+
+func TestRotatingFile_RecoversFromRenameOnWrite(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	now := time.Date(2025, time.March, 14, 15, 9, 26, 0, time.UTC)
+	f, err := NewRotatingFile(filename, RotateMaxSize(10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	f.clock = func() time.Time { return now }
+
+	_, _ = f.Write([]byte("before\n"))
+
+	// a directory stands where the backup goes, the file can't be renamed
+	if err := os.MkdirAll(filepath.Join(f.backupName(now), "taken"), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the rename fails once, the line is written all the same
+	if n, err := f.Write([]byte("after\n")); n != 6 || err == nil {
+		t.Errorf("expected 6 and an error, got %d and %v", n, err)
+	}
+	// the rotation isn't tried again until the file grew by 10 bytes more
+	if _, err := f.Write([]byte("one\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := os.RemoveAll(f.backupName(now)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.Write([]byte("two\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	assertFile(t, filename, "two\n")
+	assertFile(t, f.backupName(now), "before\nafter\none\n")
+}
+
+// tickingClock returns a clock moving forward by step each time it is read.
+func tickingClock(step time.Duration) func() time.Time {
+	now := time.Date(2025, time.March, 14, 15, 9, 26, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+// assertFile checks the contents of a file.
+func assertFile(t *testing.T, name, expected string) {
+	t.Helper()
+
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("can't read %s: %v", name, err)
+	}
+
+	if string(got) != expected {
+		t.Errorf("expected %s to hold %q, got %q", name, expected, got)
+	}
+}
+
+// assertGzipFile checks the uncompressed contents of a gzipped file.
+func assertGzipFile(t *testing.T, name, expected string) {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("can't open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("can't read %s: %v", name, err)
+	}
+
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("can't read %s: %v", name, err)
+	}
+
+	if string(got) != expected {
+		t.Errorf("expected %s to hold %q, got %q", name, expected, got)
+	}
+}
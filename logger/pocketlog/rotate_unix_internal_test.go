@@ -0,0 +1,45 @@
+//go:build unix
+
+package pocketlog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_ReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	f, err := NewRotatingFile(filename)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	f.ReopenOnSignal(syscall.SIGHUP)
+
+	moved := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(filename, moved); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("can't send SIGHUP: %v", err)
+	}
+
+	// the file is reopened asynchronously
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filename); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the file to be reopened on SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,163 @@
+package pocketlog
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// sampler limits how many times a message is written per interval.
+// Messages are told apart by their level and format string.
+type sampler struct {
+	interval   time.Duration
+	first      uint64
+	thereafter uint64
+
+	mu        sync.Mutex
+	windowEnd time.Time
+	counts    map[sampleKey]*sampleCount
+	// timer reports the suppressed messages if no message opens a new interval.
+	timer *time.Timer
+}
+
+// sampleKey identifies a repetitive message.
+type sampleKey struct {
+	level  Level
+	format string
+}
+
+// sampleCount tracks a message over the current interval.
+type sampleCount struct {
+	seen       uint64
+	suppressed uint64
+}
+
+// sampleSummary reports the messages suppressed over an interval.
+type sampleSummary struct {
+	sampleKey
+	suppressed uint64
+}
+
+// sample counts a message logged at now, and tells whether it must be written.
+// When the call starts a new interval, it also returns what was suppressed
+// over the previous one.
+func (s *sampler) sample(lvl Level, format string, now time.Time) (bool, []sampleSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []sampleSummary
+	if !now.Before(s.windowEnd) {
+		summaries = s.summarize()
+		s.counts = make(map[sampleKey]*sampleCount)
+		s.windowEnd = now.Add(s.interval)
+	}
+
+	key := sampleKey{level: lvl, format: format}
+	count, ok := s.counts[key]
+	if !ok {
+		count = &sampleCount{}
+		s.counts[key] = count
+	}
+
+	count.seen++
+	if count.seen <= s.first {
+		return true, summaries
+	}
+
+	if s.thereafter > 0 && (count.seen-s.first)%s.thereafter == 0 {
+		return true, summaries
+	}
+
+	count.suppressed++
+	return false, summaries
+}
+
+// summarize returns what was suppressed since the last summaries, and forgets it.
+// The caller must hold the lock.
+func (s *sampler) summarize() []sampleSummary {
+	var summaries []sampleSummary
+	for key, count := range s.counts {
+		if count.suppressed > 0 {
+			summaries = append(summaries, sampleSummary{sampleKey: key, suppressed: count.suppressed})
+			count.suppressed = 0
+		}
+	}
+	slices.SortFunc(summaries, func(a, b sampleSummary) int {
+		return cmp.Or(cmp.Compare(a.level, b.level), cmp.Compare(a.format, b.format))
+	})
+
+	return summaries
+}
+
+// drain returns what was suppressed since the last summaries, and forgets it.
+func (s *sampler) drain() []sampleSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.summarize()
+}
+
+// schedule calls report once an interval elapsed, unless it is already scheduled.
+func (s *sampler) schedule(report func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		return
+	}
+
+	s.timer = time.AfterFunc(s.interval, func() {
+		s.mu.Lock()
+		s.timer = nil
+		s.mu.Unlock()
+
+		report()
+	})
+}
+
+// sample tells whether the message must be written, according to the sampling
+// configured on the logger, and writes the summaries of the previous interval.
+// Once a message is suppressed, the summaries are also written an interval later,
+// in case no message opens a new interval by then.
+func (l *Logger) sample(lvl Level, format string, now time.Time) bool {
+	if l.sampler == nil || lvl == LevelFatal {
+		return true
+	}
+
+	keep, summaries := l.sampler.sample(lvl, format, now)
+	l.writeSummaries(summaries, now)
+
+	if !keep {
+		l.sampler.schedule(l.flushSummaries)
+	}
+
+	return keep
+}
+
+// flushSummaries writes what was suppressed since the last summaries.
+func (l *Logger) flushSummaries() {
+	if l.sampler == nil {
+		return
+	}
+
+	l.writeSummaries(l.sampler.drain(), l.clock())
+}
+
+// writeSummaries writes an entry for each message suppressed by the sampling.
+// These entries are neither counted, nor do they trigger the flight recorder:
+// they tell about entries which were.
+func (l *Logger) writeSummaries(summaries []sampleSummary, now time.Time) {
+	for _, summary := range summaries {
+		l.writeEntry(Entry{
+			Time:    now,
+			Level:   summary.level,
+			Message: "suppressed repeated messages",
+			Fields: []Field{
+				{Key: "sampled_format", Value: summary.format},
+				{Key: "suppressed", Value: summary.suppressed},
+			},
+			timeFormat: l.timeFormat,
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package pocketlog_test
+
+import (
+	"learn-go-pockets/logger/pocketlog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_WithSampling(t *testing.T) {
+	tw := &testWriter{}
+
+	now := fixedTime
+	testedLogger := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(func() time.Time { return now }),
+		pocketlog.WithTimeFormat(time.TimeOnly),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithSampling(time.Second, 2, 3),
+	)
+
+	for i := range 10 {
+		testedLogger.Errorf("shelf %d is empty", i)
+	}
+	// the same format on another level is sampled apart
+	testedLogger.Debugf("shelf %d is empty", 10)
+
+	now = now.Add(time.Second)
+	testedLogger.Errorf("shelf %d is empty", 11)
+	testedLogger.Infof("a different message")
+
+	expected := []string{
+		`time=15:09:26 level=error message="shelf 0 is empty"`,
+		`time=15:09:26 level=error message="shelf 1 is empty"`,
+		`time=15:09:26 level=error message="shelf 4 is empty"`,
+		`time=15:09:26 level=error message="shelf 7 is empty"`,
+		`time=15:09:26 level=debug message="shelf 10 is empty"`,
+		`time=15:09:27 level=error message="suppressed repeated messages" sampled_format="shelf %d is empty" suppressed=6`,
+		`time=15:09:27 level=error message="shelf 11 is empty"`,
+		`time=15:09:27 level=info message="a different message"`,
+	}
+
+	got := splitLines(tw.contents)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLogger_WithSamplingDropsAllAfterFirst(t *testing.T) {
+	tw := &testWriter{}
+
+	now := fixedTime
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(func() time.Time { return now }),
+		pocketlog.WithSampling(time.Minute, 1, 0),
+	)
+
+	for range 100 {
+		testedLogger.Infof(infoMessage)
+	}
+
+	if lines := splitLines(tw.contents); len(lines) != 1 {
+		t.Errorf("expected 1 log line, got %d", len(lines))
+	}
+}
+
+func TestLogger_WithSamplingNeverSamplesFatal(t *testing.T) {
+	tw := &testWriter{}
+
+	exits := 0
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithSampling(time.Minute, 1, 0),
+		pocketlog.WithExitFunc(func(int) { exits++ }),
+	)
+
+	testedLogger.Fatalf(errorMessage)
+	testedLogger.Fatalf(errorMessage)
+
+	if lines := splitLines(tw.contents); len(lines) != 2 || exits != 2 {
+		t.Errorf("expected 2 log lines and 2 exits, got %d and %d", len(lines), exits)
+	}
+}
+
+func TestLogger_WithSamplingSummaryOnFlush(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(tw),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithTimeFormat(time.TimeOnly),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithSampling(time.Hour, 1, 0),
+		pocketlog.WithFlightRecorder(10),
+	)
+
+	testedLogger.Errorf("shelf %d is empty", 0)
+	testedLogger.Debugf("looking for shelf %d", 1)
+	testedLogger.Errorf("shelf %d is empty", 1)
+	testedLogger.Errorf("shelf %d is empty", 2)
+
+	if err := testedLogger.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// nothing more was suppressed
+	_ = testedLogger.Flush()
+
+	// the summary doesn't write the debug entry kept by the flight recorder
+	expected := []string{
+		`time=15:09:26 level=error message="shelf 0 is empty"`,
+		`time=15:09:26 level=error message="suppressed repeated messages" sampled_format="shelf %d is empty" suppressed=2`,
+	}
+
+	got := splitLines(tw.contents)
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestLogger_WithSamplingSummaryAfterInterval(t *testing.T) {
+	lines := make(chanWriter, 10)
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(lines),
+		pocketlog.WithEncoder(pocketlog.LogfmtEncoder{}),
+		pocketlog.WithSampling(10*time.Millisecond, 1, 0),
+	)
+
+	for range 3 {
+		testedLogger.Infof(infoMessage)
+	}
+
+	<-lines
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `message="suppressed repeated messages"`) || !strings.HasSuffix(line, " suppressed=2\n") {
+			t.Errorf("expected a summary of 2 suppressed messages, got %s", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the summary to be written once the interval elapsed")
+	}
+}
+
+// chanWriter sends every write to the channel.
+type chanWriter chan string
+
+// Write implements the io.Writer interface.
+func (cw chanWriter) Write(p []byte) (int, error) {
+	cw <- string(p)
+	return len(p), nil
+}
@@ -0,0 +1,175 @@
+package pocketlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// sink is an output with its own encoder and threshold.
+// Sinks are shared with child loggers.
+type sink struct {
+	// mu serialises writes to the output.
+	mu        sync.Mutex
+	output    io.Writer
+	encoder   Encoder
+	threshold Level
+
+	// failures counts the consecutive failed writes to the output, and failedOver
+	// tells whether the sink gave up on it for the fallback. Both are protected by mu.
+	failures   int
+	failedOver bool
+
+	written atomic.Uint64
+	failed  atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// fallback is the output entries are written to when a sink fails to write them.
+// It is shared by the sinks of a logger, and its children.
+type fallback struct {
+	// mu serialises writes to the output, from several sinks.
+	mu     sync.Mutex
+	output io.Writer
+	// after is the number of consecutive failures after which a sink
+	// stops writing to its own output.
+	after int
+}
+
+// write prints an encoded entry to the output.
+func (f *fallback) write(p []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err := f.output.Write(p)
+	return err
+}
+
+// maxPooledBuffer is the capacity over which a buffer is left to the garbage
+// collector rather than pooled, so that a single huge entry isn't kept in memory.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers entries are encoded into. Outputs must not
+// retain what they are given to write, as the io.Writer interface requires.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// putBuffer returns the buffer to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// write encodes the entry and prints it to the output,
+// if it passes the threshold of the sink. If the output fails, the entry
+// is written to the fallback, if there is one, which replaces the output
+// once it failed too many times in a row.
+func (s *sink) write(entry Entry, fb *fallback) error {
+	if entry.Level < s.threshold {
+		return nil
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer putBuffer(buf)
+
+	if err := s.encoder.Encode(buf, entry); err != nil {
+		// fallback if the encoder fails
+		buf.Reset()
+		fmt.Fprintf(buf, "[%-6s] %s\n", entry.Level, entry.Message)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failedOver {
+		return s.writeFallback(buf.Bytes(), fb)
+	}
+
+	n, err := s.output.Write(buf.Bytes())
+	if err != nil && n == buf.Len() {
+		// the entry made it to the output, which still has an error to report,
+		// such as a RotatingFile failing to rotate
+		s.failures = 0
+		s.written.Add(1)
+		return err
+	}
+
+	if err != nil {
+		s.failed.Add(1)
+		s.failures++
+
+		if fb == nil {
+			s.dropped.Add(1)
+			return err
+		}
+
+		if s.failures >= fb.after {
+			s.failedOver = true
+		}
+
+		return errors.Join(err, s.writeFallback(buf.Bytes(), fb))
+	}
+
+	s.failures = 0
+	s.written.Add(1)
+	return nil
+}
+
+// writeFallback prints an encoded entry to the fallback.
+// The caller must hold the lock.
+func (s *sink) writeFallback(p []byte, fb *fallback) error {
+	if err := fb.write(p); err != nil {
+		s.failed.Add(1)
+		s.dropped.Add(1)
+		return fmt.Errorf("can't write to the fallback: %w", err)
+	}
+
+	s.written.Add(1)
+	return nil
+}
+
+// flush writes any buffered entry to the output, if it holds any,
+// such as an AsyncWriter does.
+func (s *sink) flush() error {
+	return flushOutput(s.output)
+}
+
+// flushOutput flushes the output if it can be.
+func flushOutput(output io.Writer) error {
+	f, ok := output.(interface{ Flush() error })
+	if !ok {
+		return nil
+	}
+	return f.Flush()
+}
+
+// WriteStats counts what happened to the entries written to the outputs
+// of a logger. An entry written to several outputs counts once for each.
+type WriteStats struct {
+	// Written counts the entries written to an output, or to the fallback.
+	Written uint64
+	// Failed counts the writes that returned an error.
+	Failed uint64
+	// Dropped counts the entries that were lost, as neither the output
+	// nor the fallback could write them.
+	Dropped uint64
+}
+
+// WriteStats returns the counts of the entries written to the outputs of the
+// logger, which are shared with the loggers derived from it, since it was created.
+func (l *Logger) WriteStats() WriteStats {
+	var stats WriteStats
+	for _, s := range l.sinks {
+		stats.Written += s.written.Load()
+		stats.Failed += s.failed.Load()
+		stats.Dropped += s.dropped.Load()
+	}
+	return stats
+}
@@ -0,0 +1,220 @@
+package pocketlog_test
+
+import (
+	"errors"
+	"learn-go-pockets/logger/pocketlog"
+	"testing"
+)
+
+func TestLogger_WithSink(t *testing.T) {
+	file := &testWriter{}
+	stderr := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithOutput(file),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithSink(stderr, pocketlog.LevelError, pocketlog.TextEncoder{}),
+	)
+
+	testedLogger.Tracef("below every threshold")
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Infof(infoMessage)
+	testedLogger.Errorf(errorMessage)
+
+	if lines := splitLines(file.contents); len(lines) != 3 {
+		t.Errorf("expected 3 entries in the file, got %d", len(lines))
+	}
+
+	expected := fixedTimeText + " [error] " + errorMessage + "\n"
+	if stderr.contents != expected {
+		t.Errorf("expected %q on stderr, got %q", expected, stderr.contents)
+	}
+}
+
+func TestLogger_WithSinksOnly(t *testing.T) {
+	file := &testWriter{}
+	stderr := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelDebug,
+		pocketlog.WithSink(file, pocketlog.LevelDebug, pocketlog.JSONEncoder{}),
+		pocketlog.WithSink(stderr, pocketlog.LevelError, pocketlog.TextEncoder{}),
+	)
+
+	testedLogger.Debugf(debugMessage)
+	testedLogger.Errorf(errorMessage)
+
+	if lines := splitLines(file.contents); len(lines) != 2 {
+		t.Errorf("expected 2 entries in the file, got %d", len(lines))
+	}
+	if lines := splitLines(stderr.contents); len(lines) != 1 {
+		t.Errorf("expected 1 entry on stderr, got %d", len(lines))
+	}
+
+	// nothing was written to Stdout
+	expected := pocketlog.WriteStats{Written: 3}
+	if got := testedLogger.WriteStats(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestLogger_WithSinkFailing(t *testing.T) {
+	tw := &testWriter{}
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(failingWriter{err: errors.New("broken pipe")}),
+		pocketlog.WithSink(tw, pocketlog.LevelInfo, pocketlog.LogfmtEncoder{}),
+		pocketlog.WithSink(failingWriter{err: errors.New("disk full")}, pocketlog.LevelInfo, pocketlog.JSONEncoder{}),
+	)
+
+	testedLogger.Infof(infoMessage)
+	testedLogger.Errorf(errorMessage)
+
+	if lines := splitLines(tw.contents); len(lines) != 2 {
+		t.Errorf("expected 2 entries despite the failing sinks, got %d", len(lines))
+	}
+}
+
+func TestLogger_FlushSinks(t *testing.T) {
+	first, second := &testWriter{}, &testWriter{}
+	firstAsync := pocketlog.NewAsyncWriter(first, 8, pocketlog.OverflowBlock)
+	defer firstAsync.Close()
+	secondAsync := pocketlog.NewAsyncWriter(second, 8, pocketlog.OverflowBlock)
+	defer secondAsync.Close()
+
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(firstAsync),
+		pocketlog.WithSink(secondAsync, pocketlog.LevelInfo, pocketlog.JSONEncoder{}),
+	)
+
+	testedLogger.Infof(infoMessage)
+
+	if err := testedLogger.Flush(); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+
+	if first.contents == "" || first.contents != second.contents {
+		t.Errorf("expected both sinks to be flushed, got %q and %q", first.contents, second.contents)
+	}
+}
+
+func TestLogger_WithErrorHandler(t *testing.T) {
+	var errs []error
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(failingWriter{err: errors.New("broken pipe")}),
+		pocketlog.WithSink(&testWriter{}, pocketlog.LevelInfo, pocketlog.JSONEncoder{}),
+		pocketlog.WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+
+	testedLogger.Infof(infoMessage)
+	testedLogger.Errorf(errorMessage)
+
+	if len(errs) != 2 || errs[0].Error() != "broken pipe" {
+		t.Errorf("expected 2 broken pipe errors, got %v", errs)
+	}
+
+	expected := pocketlog.WriteStats{Written: 2, Failed: 2, Dropped: 2}
+	if got := testedLogger.WriteStats(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestLogger_WithFallback(t *testing.T) {
+	output := &flakyWriter{err: errors.New("disk full")}
+	stderr := &testWriter{}
+
+	var errs []error
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(output),
+		pocketlog.WithClock(fixedClock),
+		pocketlog.WithEncoder(pocketlog.TextEncoder{}),
+		pocketlog.WithFallback(stderr, 2),
+		pocketlog.WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+
+	testedLogger.Infof("first")
+	testedLogger.Infof("second")
+	testedLogger.Infof("third")
+
+	if output.calls != 2 {
+		t.Errorf("expected the output to be given up on after 2 failures, got %d calls", output.calls)
+	}
+
+	expected := fixedTimeText + " [info ] first\n" +
+		fixedTimeText + " [info ] second\n" +
+		fixedTimeText + " [info ] third\n"
+	if stderr.contents != expected {
+		t.Errorf("expected %q on the fallback, got %q", expected, stderr.contents)
+	}
+
+	if len(errs) != 2 {
+		t.Errorf("expected the 2 failures to be reported, got %v", errs)
+	}
+
+	expectedStats := pocketlog.WriteStats{Written: 3, Failed: 2}
+	if got := testedLogger.WriteStats(); got != expectedStats {
+		t.Errorf("expected %+v, got %+v", expectedStats, got)
+	}
+}
+
+func TestLogger_WithFallbackFailing(t *testing.T) {
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(failingWriter{err: errors.New("broken pipe")}),
+		pocketlog.WithFallback(failingWriter{err: errors.New("closed")}, 1),
+		pocketlog.WithSink(&testWriter{}, pocketlog.LevelError, pocketlog.JSONEncoder{}),
+	)
+
+	testedLogger.Infof(infoMessage)
+	testedLogger.Errorf(errorMessage)
+
+	// the first write fails twice, the second only once, as the output was given up on
+	expected := pocketlog.WriteStats{Written: 1, Failed: 3, Dropped: 2}
+	if got := testedLogger.WriteStats(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+// flakyWriter is an io.Writer that always fails, counting the calls.
+type flakyWriter struct {
+	calls int
+	err   error
+}
+
+// Write implements the io.Writer interface.
+func (fw *flakyWriter) Write([]byte) (int, error) {
+	fw.calls++
+	return 0, fw.err
+}
+
+func TestLogger_WithFallbackWrittenWithError(t *testing.T) {
+	var errs []error
+	stderr := &testWriter{}
+	testedLogger := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(writtenWithErrorWriter{err: errors.New("can't rotate")}),
+		pocketlog.WithFallback(stderr, 1),
+		pocketlog.WithErrorHandler(func(err error) { errs = append(errs, err) }),
+	)
+
+	testedLogger.Infof(infoMessage)
+
+	if stderr.contents != "" {
+		t.Errorf("expected nothing on the fallback, got %s", stderr.contents)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected the error to be reported, got %v", errs)
+	}
+
+	expected := pocketlog.WriteStats{Written: 1}
+	if got := testedLogger.WriteStats(); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+// writtenWithErrorWriter is an io.Writer that writes everything, and still returns an error.
+type writtenWithErrorWriter struct {
+	err error
+}
+
+// Write implements the io.Writer interface.
+func (w writtenWithErrorWriter) Write(p []byte) (int, error) {
+	return len(p), w.err
+}
@@ -0,0 +1,174 @@
+package pocketlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLevel returns the slog level matching lvl.
+// Trace and fatal, which slog doesn't define, sit 4 below debug
+// and 4 above error respectively.
+func (lvl Level) SlogLevel() slog.Level {
+	switch lvl {
+	case LevelTrace:
+		return slog.LevelDebug - 4
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelError + 4
+	}
+}
+
+// LevelFromSlog returns the level matching a slog level. Levels in between
+// are rounded down, e.g. slog.LevelInfo+2 is LevelInfo.
+func LevelFromSlog(lvl slog.Level) Level {
+	switch {
+	case lvl < slog.LevelDebug:
+		return LevelTrace
+	case lvl < slog.LevelInfo:
+		return LevelDebug
+	case lvl < slog.LevelWarn:
+		return LevelInfo
+	case lvl < slog.LevelError:
+		return LevelWarn
+	case lvl < slog.LevelError+4:
+		return LevelError
+	default:
+		return LevelFatal
+	}
+}
+
+// SlogHandler is a slog.Handler writing records through a Logger,
+// so that code using log/slog shares the logger's threshold, output and fields.
+type SlogHandler struct {
+	lgr *Logger
+	// prefix holds the opened groups, joined and followed by a dot.
+	prefix string
+}
+
+// NewSlogHandler returns a slog.Handler writing records through lgr.
+// Attributes nested in groups are written as fields with dotted keys,
+// such as "request.id". Entries are timestamped with the logger's clock rather
+// than the record's time. Records at slog's fatal level don't exit the process.
+func NewSlogHandler(lgr *Logger) *SlogHandler {
+	return &SlogHandler{lgr: lgr}
+}
+
+// Enabled implements the slog.Handler interface.
+func (h *SlogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return h.lgr.Enabled(LevelFromSlog(lvl))
+}
+
+// Handle implements the slog.Handler interface.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	now := h.lgr.clock()
+	lvl := LevelFromSlog(r.Level)
+	if !h.lgr.sample(lvl, r.Message, now) {
+		h.lgr.counts.suppress(lvl)
+		return nil
+	}
+
+	var fields []Field
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	entry := Entry{
+		Time:       now,
+		Level:      lvl,
+		Message:    r.Message,
+		Logger:     h.lgr.name,
+		Fields:     mergeFields(h.lgr.withContextFields(ctx), fields),
+		timeFormat: h.lgr.timeFormat,
+	}
+
+	if h.lgr.caller {
+		// slog already found the caller, whatever the skip depth
+		entry.setCaller(r.PC)
+	}
+
+	h.lgr.write(entry)
+
+	return nil
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.prefix, a)
+	}
+
+	return &SlogHandler{lgr: h.lgr.With(fields...), prefix: h.prefix}
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &SlogHandler{lgr: h.lgr, prefix: h.prefix + name + "."}
+}
+
+// appendAttr appends the attribute to fields, flattening groups into dotted keys.
+// As slog handlers should, it ignores empty attributes and inlines groups without a key.
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
+
+// forwardToSlog writes the entry as a record of the slog handler.
+func forwardToSlog(h slog.Handler, e Entry) {
+	ctx := context.Background()
+
+	lvl := e.Level.SlogLevel()
+	if !h.Enabled(ctx, lvl) {
+		return
+	}
+
+	r := slog.NewRecord(e.Time, lvl, e.Message, e.pc)
+	if e.Logger != "" {
+		r.AddAttrs(slog.String("logger", e.Logger))
+	}
+	for _, f := range e.Fields {
+		r.AddAttrs(fieldAttr(f))
+	}
+
+	_ = h.Handle(ctx, r)
+}
+
+// fieldAttr returns the field as an slog attribute, where groups are slog groups.
+func fieldAttr(f Field) slog.Attr {
+	group, ok := f.Value.(Fields)
+	if !ok {
+		return slog.Any(f.Key, f.Value)
+	}
+
+	attrs := make([]slog.Attr, 0, len(group))
+	for _, gf := range group {
+		attrs = append(attrs, fieldAttr(gf))
+	}
+	return slog.Attr{Key: f.Key, Value: slog.GroupValue(attrs...)}
+}
@@ -0,0 +1,117 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"context"
+	"learn-go-pockets/logger/pocketlog"
+	"log/slog"
+	"testing"
+)
+
+func ExampleNewSlogHandler() {
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithClock(fixedClock))
+
+	slogger := slog.New(pocketlog.NewSlogHandler(lgr))
+	slogger.Info("Shelf loaded", "shelf", "sci-fi", "books", 3)
+	// Output:
+	// {"time":"2025-03-14T15:09:26.535897932Z","level":"info","message":"Shelf loaded","shelf":"sci-fi","books":3}
+}
+
+func TestSlogHandler(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock)).
+		With(pocketlog.Field{Key: "service", Value: "bookworms"})
+
+	slogger := slog.New(pocketlog.NewSlogHandler(lgr)).
+		With("user", "ada").
+		WithGroup("request").
+		With("id", "f3a9")
+
+	slogger.Warn("slow request",
+		slog.Int("status", 200),
+		slog.Group("timing", slog.Int("db_ms", 12)),
+		slog.Group("", slog.Bool("inlined", true)),
+		slog.Attr{},
+	)
+	slogger.Log(context.Background(), slog.LevelInfo+2, "rounded down to info")
+	slogger.Log(context.Background(), slog.LevelDebug-4, "trace is below the threshold")
+
+	expected := `{"time":"` + fixedTimeText + `","level":"warn","message":"slow request",` +
+		`"service":"bookworms","user":"ada","request.id":"f3a9","request.status":200,"request.timing.db_ms":12,"request.inlined":true}` + "\n" +
+		`{"time":"` + fixedTimeText + `","level":"info","message":"rounded down to info",` +
+		`"service":"bookworms","user":"ada","request.id":"f3a9"}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, tw.contents)
+	}
+}
+
+func TestSlogHandler_Enabled(t *testing.T) {
+	h := pocketlog.NewSlogHandler(pocketlog.New(pocketlog.LevelWarn))
+
+	tests := map[slog.Level]bool{
+		slog.LevelDebug:    false,
+		slog.LevelInfo:     false,
+		slog.LevelInfo + 2: false,
+		slog.LevelWarn:     true,
+		slog.LevelError:    true,
+	}
+
+	for lvl, expected := range tests {
+		if got := h.Enabled(context.Background(), lvl); got != expected {
+			t.Errorf("expected Enabled(%v) to be %t, got %t", lvl, expected, got)
+		}
+	}
+}
+
+func TestLevelSlogMapping(t *testing.T) {
+	for _, lvl := range []pocketlog.Level{
+		pocketlog.LevelTrace,
+		pocketlog.LevelDebug,
+		pocketlog.LevelInfo,
+		pocketlog.LevelWarn,
+		pocketlog.LevelError,
+		pocketlog.LevelFatal,
+	} {
+		if got := pocketlog.LevelFromSlog(lvl.SlogLevel()); got != lvl {
+			t.Errorf("expected %v to map back to itself, got %v", lvl, got)
+		}
+	}
+}
+
+func TestWithSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug - 4,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	lgr := pocketlog.New(pocketlog.LevelDebug, pocketlog.WithSlogHandler(h)).
+		With(pocketlog.Field{Key: "shelf", Value: "sci-fi"})
+
+	lgr.Tracef("below the logger threshold")
+	lgr.Debugf("Loading %d books", 3)
+	lgr.Warnf("Shelf is almost full")
+
+	expected := "level=DEBUG msg=\"Loading 3 books\" shelf=sci-fi\n" +
+		"level=WARN msg=\"Shelf is almost full\" shelf=sci-fi\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
+func TestWithSlogHandler_HandlerThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError})
+
+	lgr := pocketlog.New(pocketlog.LevelTrace, pocketlog.WithSlogHandler(h))
+	lgr.Infof("filtered by the handler")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %s", buf.String())
+	}
+}
@@ -0,0 +1,130 @@
+package pocketlog
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// stdLogDepth is the number of frames between the Write method of a StdLogWriter
+// and the code calling the log package, such as log.Printf: log.Printf itself
+// and the output method of log.Logger.
+const stdLogDepth = 2
+
+// StdLogWriter is an io.Writer logging each write as a message of a Logger,
+// at a given level. It is meant to be the output of a log.Logger, which writes
+// each message in a single call, as set up by NewStdLogger and RedirectStdLog.
+// The prefix, date, time and file the log.Logger writes before the message
+// are left out, as entries have their own: call SetLogger to tell the writer
+// which log.Logger writes to it.
+type StdLogWriter struct {
+	lgr   *Logger
+	level Level
+	// owner is the log.Logger writing to the writer, if known.
+	owner atomic.Pointer[log.Logger]
+}
+
+// NewStdLogWriter returns a StdLogWriter logging through l at the given level.
+func NewStdLogWriter(l *Logger, level Level) *StdLogWriter {
+	lgr := *l
+	// report the code calling the log package, not the log package itself
+	lgr.callerSkip += stdLogDepth
+
+	return &StdLogWriter{lgr: &lgr, level: level}
+}
+
+// SetLogger tells the writer which log.Logger writes to it, so that the header
+// it writes, according to its current prefix and flags, is left out of the messages.
+func (w *StdLogWriter) SetLogger(owner *log.Logger) {
+	w.owner.Store(owner)
+}
+
+// Write implements the io.Writer interface.
+// The header written by the log.Logger, and the final newline it adds,
+// are trimmed from the message.
+func (w *StdLogWriter) Write(p []byte) (int, error) {
+	if w.lgr.discards(w.level) {
+		return len(p), nil
+	}
+
+	msg := strings.TrimSuffix(string(p), "\n")
+	if owner := w.owner.Load(); owner != nil {
+		msg = trimStdLogHeader(msg, owner.Prefix(), owner.Flags())
+	}
+	w.lgr.logf(context.Background(), w.level, "%s", msg)
+
+	if w.level == LevelFatal {
+		// log.Fatal exits on its own, the entries must be written by then
+		_ = w.lgr.Flush()
+	}
+
+	return len(p), nil
+}
+
+// trimStdLogHeader returns the message without the header a log.Logger
+// with the given prefix and flags writes before it, as the log package does.
+func trimStdLogHeader(msg, prefix string, flags int) string {
+	if flags&log.Lmsgprefix == 0 {
+		msg = strings.TrimPrefix(msg, prefix)
+	}
+
+	if flags&log.Ldate != 0 {
+		// 2009/01/23 and a space
+		msg = msg[min(len(msg), 11):]
+	}
+	if flags&(log.Ltime|log.Lmicroseconds) != 0 {
+		// 01:23:23, .123123 and a space
+		width := 9
+		if flags&log.Lmicroseconds != 0 {
+			width += 7
+		}
+		msg = msg[min(len(msg), width):]
+	}
+	if flags&(log.Lshortfile|log.Llongfile) != 0 {
+		// file.go:23 and a colon
+		if _, after, ok := strings.Cut(msg, ": "); ok {
+			msg = after
+		}
+	}
+
+	if flags&log.Lmsgprefix != 0 {
+		msg = strings.TrimPrefix(msg, prefix)
+	}
+
+	return msg
+}
+
+// NewStdLogger returns a log.Logger logging through l at the given level,
+// for code expecting one, such as http.Server.ErrorLog. Its prefix and flags
+// can be changed, the header they make is left out of the messages.
+func NewStdLogger(l *Logger, level Level) *log.Logger {
+	w := NewStdLogWriter(l, level)
+	stdLogger := log.New(w, "", 0)
+	w.SetLogger(stdLogger)
+
+	return stdLogger
+}
+
+// RedirectStdLog routes the output of the standard logger of the log package,
+// as used by log.Printf, through l at the given level. The flags and prefix of
+// the standard logger are cleared, as the entries have their own time; should
+// they be set again, the header they make is left out of the messages.
+// Calling the returned function restores the output, flags and prefix
+// the standard logger had before.
+func RedirectStdLog(l *Logger, level Level) (undo func()) {
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+
+	w := NewStdLogWriter(l, level)
+	w.SetLogger(log.Default())
+
+	log.SetOutput(w)
+	log.SetFlags(0)
+	log.SetPrefix("")
+
+	return func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
@@ -0,0 +1,122 @@
+package pocketlog_test
+
+import (
+	"bytes"
+	"learn-go-pockets/logger/pocketlog"
+	"log"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	})
+
+	var previous bytes.Buffer
+	log.SetOutput(&previous)
+	log.SetFlags(log.Lshortfile)
+	log.SetPrefix("legacy: ")
+
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
+
+	undo := pocketlog.RedirectStdLog(lgr, pocketlog.LevelWarn)
+	log.Printf("%d shelves are missing", 2)
+	log.Println("Multi\nline")
+	undo()
+
+	expected := `{"time":"` + fixedTimeText + `","level":"warn","message":"2 shelves are missing"}` + "\n" +
+		`{"time":"` + fixedTimeText + `","level":"warn","message":"Multi\nline"}` + "\n"
+	if tw.contents != expected {
+		t.Errorf("expected %s, got %s", expected, tw.contents)
+	}
+
+	if log.Flags() != log.Lshortfile || log.Prefix() != "legacy: " {
+		t.Errorf("expected the flags and prefix to be restored, got %d and %q", log.Flags(), log.Prefix())
+	}
+
+	log.Print("restored")
+	if !bytes.HasSuffix(previous.Bytes(), []byte(" restored\n")) {
+		t.Errorf("expected the output to be restored, got %q", previous.String())
+	}
+}
+
+func TestRedirectStdLog_Header(t *testing.T) {
+	output, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	})
+
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
+
+	undo := pocketlog.RedirectStdLog(lgr, pocketlog.LevelWarn)
+	defer undo()
+
+	// third-party code sets flags and prefix again
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+	log.SetPrefix("legacy: ")
+	log.Print("shelf: missing")
+
+	log.SetFlags(log.LstdFlags | log.Lmsgprefix)
+	log.Print("shelf: missing")
+
+	expected := `{"time":"` + fixedTimeText + `","level":"warn","message":"shelf: missing"}` + "\n"
+	if tw.contents != expected+expected {
+		t.Errorf("expected %s, got %s", expected+expected, tw.contents)
+	}
+}
+
+func TestNewStdLogger_Header(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithClock(fixedClock))
+
+	stdLogger := pocketlog.NewStdLogger(lgr, pocketlog.LevelInfo)
+	stdLogger.SetPrefix("x: ")
+	stdLogger.SetFlags(log.LstdFlags | log.LUTC)
+	stdLogger.Print(infoMessage)
+
+	w := pocketlog.NewStdLogWriter(lgr, pocketlog.LevelInfo)
+	custom := log.New(w, "x: ", log.Ldate|log.Llongfile)
+	w.SetLogger(custom)
+	custom.Print(infoMessage)
+
+	expected := `{"time":"` + fixedTimeText + `","level":"info","message":"` + infoMessage + `"}` + "\n"
+	if tw.contents != expected+expected {
+		t.Errorf("expected %s, got %s", expected+expected, tw.contents)
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw), pocketlog.WithCaller(0))
+
+	pocketlog.NewStdLogger(lgr, pocketlog.LevelError).Print(errorMessage)
+	expectedCaller := callerLine(-1)
+
+	assertCaller(t, tw.contents, expectedCaller, "learn-go-pockets/logger/pocketlog_test.TestNewStdLogger")
+}
+
+func TestStdLogWriter_Threshold(t *testing.T) {
+	tw := &testWriter{}
+	lgr := pocketlog.New(pocketlog.LevelInfo, pocketlog.WithOutput(tw))
+
+	stdLogger := pocketlog.NewStdLogger(lgr, pocketlog.LevelDebug)
+	stdLogger.Print(debugMessage)
+
+	if tw.contents != "" {
+		t.Errorf("expected no entry below the threshold, got %s", tw.contents)
+	}
+
+	lgr.SetLevel(pocketlog.LevelDebug)
+	stdLogger.Print(debugMessage)
+
+	if lines := splitLines(tw.contents); len(lines) != 1 {
+		t.Errorf("expected 1 entry once the threshold is lowered, got %d", len(lines))
+	}
+}
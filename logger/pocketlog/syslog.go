@@ -0,0 +1,300 @@
+package pocketlog
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SyslogFacility is the facility of a syslog message, the kind of program that sent it.
+type SyslogFacility byte
+
+const (
+	// FacilityUser is for user-level messages. This is the default.
+	FacilityUser SyslogFacility = 1
+	// FacilityDaemon is for system daemons.
+	FacilityDaemon SyslogFacility = 3
+)
+
+// FacilityLocal0 to FacilityLocal7 are reserved for local use.
+const (
+	FacilityLocal0 SyslogFacility = iota + 16
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// syslogTimeFormat is the RFC 5424 timestamp, which allows up to microseconds.
+const syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// defaultSDID is the ID of the structured data element holding the fields.
+// 32473 is the enterprise number reserved for documentation.
+const defaultSDID = "fields@32473"
+
+// SyslogSeverity returns the syslog severity matching lvl, from 7 for debug
+// down to 2, critical, for fatal. Trace is reported as debug.
+func (lvl Level) SyslogSeverity() int {
+	switch lvl {
+	case LevelTrace, LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarn:
+		return 4
+	case LevelError:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// SyslogEncoder writes each entry as an RFC 5424 syslog message.
+// The fields of the entry are written as the parameters of a structured data element,
+// and the message is not terminated by a newline: the framing is up to the
+// transport, see DialSyslog.
+type SyslogEncoder struct {
+	// Facility defaults to FacilityUser.
+	Facility SyslogFacility
+	// Hostname defaults to the name reported by the kernel.
+	Hostname string
+	// AppName is the name of the program, and defaults to the name of its executable.
+	AppName string
+	// ProcID defaults to the process ID.
+	ProcID string
+	// SDID is the ID of the structured data element, and defaults to "fields@32473".
+	SDID string
+}
+
+// Encode implements the Encoder interface.
+func (enc SyslogEncoder) Encode(buf *bytes.Buffer, e Entry) error {
+	facility := enc.Facility
+	if facility == 0 {
+		facility = FacilityUser
+	}
+
+	fmt.Fprintf(buf, "<%d>1 ", int(facility)*8+e.Level.SyslogSeverity())
+	buf.Write(e.Time.AppendFormat(buf.AvailableBuffer(), syslogTimeFormat))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderField(orDefault(enc.Hostname, hostname), 255))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderField(orDefault(enc.AppName, appName), 48))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderField(orDefault(enc.ProcID, procID), 128))
+	// no MSGID
+	buf.WriteString(" - ")
+
+	enc.appendStructuredData(buf, e)
+
+	if e.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(e.Message)
+	}
+
+	return nil
+}
+
+// appendStructuredData writes the caller and fields of the entry as a structured
+// data element, or the nil value if there are none.
+func (enc SyslogEncoder) appendStructuredData(buf *bytes.Buffer, e Entry) {
+	if e.Logger == "" && e.Caller == "" && len(e.Fields) == 0 {
+		buf.WriteByte('-')
+		return
+	}
+
+	sdID := enc.SDID
+	if sdID == "" {
+		sdID = defaultSDID
+	}
+
+	buf.WriteByte('[')
+	buf.WriteString(syslogName(sdID))
+
+	if e.Logger != "" {
+		appendSDParam(buf, "logger", e.Logger)
+	}
+
+	if e.Caller != "" {
+		appendSDParam(buf, "caller", e.Caller)
+		appendSDParam(buf, "func", e.Func)
+	}
+
+	walkFields("", e.Fields, func(key string, value any) {
+		appendSDParam(buf, key, valueText(value))
+	})
+
+	buf.WriteByte(']')
+}
+
+// appendSDParam writes a structured data parameter,
+// escaping the characters RFC 5424 requires in its value.
+func appendSDParam(buf *bytes.Buffer, name, value string) {
+	buf.WriteByte(' ')
+	buf.WriteString(syslogName(name))
+	buf.WriteString(`="`)
+	for _, r := range value {
+		if r == '"' || r == '\\' || r == ']' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('"')
+}
+
+// syslogName returns the name of a structured data element or parameter:
+// at most 32 printable ASCII characters, none of which is =, space, ] or ".
+func syslogName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// syslogHeaderField returns the value of a header field, at most max printable
+// ASCII characters without space, or the nil value if it is empty.
+func syslogHeaderField(value string, max int) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+
+	if len(value) > max {
+		value = value[:max]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// orDefault returns value, or what def returns if value is empty.
+func orDefault(value string, def func() string) string {
+	if value != "" {
+		return value
+	}
+	return def()
+}
+
+// hostname returns the name of the host.
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+// appName returns the name of the executable.
+func appName() string {
+	name, _ := os.Executable()
+	return filepath.Base(name)
+}
+
+// procID returns the process ID.
+func procID() string {
+	return strconv.Itoa(os.Getpid())
+}
+
+// SyslogWriter is an io.WriteCloser sending each write as a message to a syslog server.
+// On stream connections, messages are framed by octet counting (RFC 6587).
+// The connection is dialled again once if a write fails, as the server,
+// or journald, may have restarted.
+// It is safe for concurrent use.
+type SyslogWriter struct {
+	network string
+	address string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// DialSyslog connects to a syslog server, or to a journald socket, on the
+// given network: "udp", "tcp", "unixgram" or "unix", as with net.Dial.
+func DialSyslog(network, address string) (*SyslogWriter, error) {
+	w := &SyslogWriter{network: network, address: address}
+
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write implements the io.Writer interface. Each write is a single message.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return 0, ErrClosed
+	}
+
+	msg := p
+	if w.isStream() {
+		msg = append(strconv.AppendInt(nil, int64(len(p)), 10), ' ')
+		msg = append(msg, p...)
+	}
+
+	_, err := w.conn.Write(msg)
+	if err != nil {
+		// the server may have closed the connection, or restarted
+		// and bound a new socket: try again once
+		_ = w.conn.Close()
+		if err = w.dial(); err == nil {
+			_, err = w.conn.Write(msg)
+		}
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection.
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return ErrClosed
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// dial opens the connection. The caller must hold the lock, if there is a connection.
+func (w *SyslogWriter) dial() error {
+	conn, err := net.Dial(w.network, w.address)
+	if err != nil {
+		return fmt.Errorf("can't dial syslog: %w", err)
+	}
+
+	w.conn = conn
+	return nil
+}
+
+// isStream tells whether messages must be framed, as they travel on a stream.
+func (w *SyslogWriter) isStream() bool {
+	return strings.HasPrefix(w.network, "tcp") || w.network == "unix"
+}
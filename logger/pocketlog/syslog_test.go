@@ -0,0 +1,223 @@
+package pocketlog_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"learn-go-pockets/logger/pocketlog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSyslogEncoder has fixed header fields, for reproducible messages.
+var testSyslogEncoder = pocketlog.SyslogEncoder{
+	Hostname: "shelf.local",
+	AppName:  "bookworms",
+	ProcID:   "42",
+}
+
+func TestSyslogEncoder(t *testing.T) {
+	tests := map[string]struct {
+		encoder  pocketlog.SyslogEncoder
+		entry    pocketlog.Entry
+		expected string
+	}{
+		"no fields": {
+			encoder:  testSyslogEncoder,
+			entry:    pocketlog.Entry{Time: fixedTime, Level: pocketlog.LevelInfo, Message: "Shelf loaded"},
+			expected: "<14>1 2025-03-14T15:09:26.535897Z shelf.local bookworms 42 - - Shelf loaded",
+		},
+		"fields as structured data": {
+			encoder: testSyslogEncoder,
+			entry: pocketlog.Entry{
+				Time:    fixedTime,
+				Level:   pocketlog.LevelError,
+				Message: "Shelf not found",
+				Fields: []pocketlog.Field{
+					{Key: "shelf", Value: `sci-fi "classics" [1]`},
+					{Key: "count", Value: 3},
+					{Key: "bad key=", Value: `C:\`},
+				},
+			},
+			expected: `<11>1 2025-03-14T15:09:26.535897Z shelf.local bookworms 42 - ` +
+				`[fields@32473 shelf="sci-fi \"classics\" [1\]" count="3" bad_key_="C:\\"] Shelf not found`,
+		},
+		"facility and caller": {
+			encoder: pocketlog.SyslogEncoder{
+				Facility: pocketlog.FacilityLocal3,
+				Hostname: "a host with spaces",
+				AppName:  "bookworms",
+				ProcID:   "42",
+				SDID:     "app@12345",
+			},
+			entry: pocketlog.Entry{
+				Time:    fixedTime.In(time.FixedZone("CET", 3600)),
+				Level:   pocketlog.LevelFatal,
+				Message: "Out of shelves",
+				Caller:  "bookworms/main.go:12",
+				Func:    "main.main",
+			},
+			expected: `<154>1 2025-03-14T16:09:26.535897+01:00 a_host_with_spaces bookworms 42 - ` +
+				`[app@12345 caller="bookworms/main.go:12" func="main.main"] Out of shelves`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.encoder.Encode(&buf, tc.entry); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if buf.String() != tc.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tc.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestLevel_SyslogSeverity(t *testing.T) {
+	expected := map[pocketlog.Level]int{
+		pocketlog.LevelTrace: 7,
+		pocketlog.LevelDebug: 7,
+		pocketlog.LevelInfo:  6,
+		pocketlog.LevelWarn:  4,
+		pocketlog.LevelError: 3,
+		pocketlog.LevelFatal: 2,
+	}
+
+	for lvl, severity := range expected {
+		if got := lvl.SyslogSeverity(); got != severity {
+			t.Errorf("expected %v to have severity %d, got %d", lvl, severity, got)
+		}
+	}
+}
+
+func TestDialSyslog_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can't listen on UDP: %v", err)
+	}
+	defer conn.Close()
+
+	w, err := pocketlog.DialSyslog("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(w),
+		pocketlog.WithEncoder(testSyslogEncoder),
+		pocketlog.WithClock(fixedClock),
+	)
+	lgr.Warnf("Shelf %s is almost full", "sci-fi")
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	packet := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(packet)
+	if err != nil {
+		t.Fatalf("can't read message: %v", err)
+	}
+
+	expected := "<12>1 2025-03-14T15:09:26.535897Z shelf.local bookworms 42 - - Shelf sci-fi is almost full"
+	if string(packet[:n]) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, packet[:n])
+	}
+}
+
+func TestDialSyslog_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can't listen on TCP: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		received <- readOctetCounted(conn, 2)
+	}()
+
+	w, err := pocketlog.DialSyslog("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	lgr := pocketlog.New(pocketlog.LevelInfo,
+		pocketlog.WithOutput(w),
+		pocketlog.WithEncoder(testSyslogEncoder),
+		pocketlog.WithClock(fixedClock),
+	)
+	lgr.Infof("first")
+	lgr.With(pocketlog.Field{Key: "multi", Value: "line\nvalue"}).Infof("second")
+
+	select {
+	case got := <-received:
+		expected := []string{
+			"<14>1 2025-03-14T15:09:26.535897Z shelf.local bookworms 42 - - first",
+			"<14>1 2025-03-14T15:09:26.535897Z shelf.local bookworms 42 - [fields@32473 multi=\"line\nvalue\"] second",
+		}
+		if strings.Join(got, "|") != strings.Join(expected, "|") {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for messages")
+	}
+}
+
+func TestSyslogWriter_Closed(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can't listen on UDP: %v", err)
+	}
+	defer conn.Close()
+
+	w, err := pocketlog.DialSyslog("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+
+	if _, err := w.Write([]byte("too late")); err != pocketlog.ErrClosed {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+// readOctetCounted reads n messages framed as "LENGTH SP MESSAGE".
+func readOctetCounted(r io.Reader, n int) []string {
+	br := bufio.NewReader(r)
+
+	var messages []string
+	for range n {
+		length, err := br.ReadString(' ')
+		if err != nil {
+			return messages
+		}
+
+		size, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		if err != nil {
+			return messages
+		}
+
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(br, msg); err != nil {
+			return messages
+		}
+		messages = append(messages, string(msg))
+	}
+
+	return messages
+}
@@ -0,0 +1,43 @@
+package pocketlog
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// TextEncoder writes each entry as a human-readable line, meant for local development.
+// Levels are padded so that messages are aligned, and fields follow the message
+// as logfmt pairs.
+type TextEncoder struct{}
+
+// Encode implements the Encoder interface.
+func (TextEncoder) Encode(buf *bytes.Buffer, e Entry) error {
+	buf.Write(e.appendTime(buf.AvailableBuffer()))
+	fmt.Fprintf(buf, " [%-5s] ", e.Level)
+	if e.Logger != "" {
+		buf.WriteString(e.Logger)
+		buf.WriteString(": ")
+	}
+	if e.Caller != "" {
+		buf.WriteString(e.Caller)
+		buf.WriteByte(' ')
+	}
+	appendEscaped(buf, e.Message)
+
+	first := true
+	walkFields("", e.Fields, func(key string, value any) {
+		if first {
+			buf.WriteString("  ")
+			first = false
+		} else {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(logfmtKey(fieldKey(key)))
+		buf.WriteByte('=')
+		appendLogfmtValue(buf, valueText(value))
+	})
+
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,12 @@
+package pocketlog
+
+import "time"
+
+const (
+	// TimeFormatRFC3339Nano writes timestamps as RFC 3339 strings
+	// with nanosecond precision. This is the default.
+	TimeFormatRFC3339Nano = time.RFC3339Nano
+	// TimeFormatUnixMilli writes timestamps as the number of
+	// milliseconds elapsed since January 1, 1970 UTC.
+	TimeFormatUnixMilli = "unixmilli"
+)